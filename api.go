@@ -5,21 +5,21 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/manyminds/api2go/jsonapi"
-	"github.com/manyminds/api2go/routing"
 )
 
 const (
-	codeInvalidQueryFields  = "API2GO_INVALID_FIELD_QUERY_PARAM"
-	defaultContentTypHeader = "application/vnd.api+json"
+	codeInvalidQueryFields     = "API2GO_INVALID_FIELD_QUERY_PARAM"
+	codeInvalidQueryPagination = "API2GO_INVALID_PAGINATION_QUERY_PARAM"
+	defaultContentTypHeader    = "application/vnd.api+json"
 )
 
 var (
@@ -28,8 +28,10 @@ var (
 )
 
 type information struct {
-	prefix   string
-	resolver URLResolver
+	prefix    string
+	resolver  URLResolver
+	api       *API
+	parentIDs map[string]string
 }
 
 func (i information) GetBaseURL() string {
@@ -40,8 +42,20 @@ func (i information) GetPrefix() string {
 	return i.prefix
 }
 
+// GetRelationshipPath returns the path of a relationship as configured with RelationshipPath
+func (i information) GetRelationshipPath(structType, name string) string {
+	if i.api != nil {
+		if res := i.api.resourceByName(structType); res != nil {
+			return res.config.relationshipPath(name)
+		}
+	}
+
+	return name
+}
+
 type paginationQueryParams struct {
 	number, size, offset, limit string
+	after, before               string
 }
 
 func newPaginationQueryParams(r *http.Request) paginationQueryParams {
@@ -52,11 +66,30 @@ func newPaginationQueryParams(r *http.Request) paginationQueryParams {
 	result.size = queryParams.Get("page[size]")
 	result.offset = queryParams.Get("page[offset]")
 	result.limit = queryParams.Get("page[limit]")
+	result.after = queryParams.Get("page[after]")
+	result.before = queryParams.Get("page[before]")
 
 	return result
 }
 
+func (p paginationQueryParams) isEmpty() bool {
+	return p.number == "" && p.size == "" && p.offset == "" && p.limit == "" && p.after == "" && p.before == ""
+}
+
+// isCursor returns true if the params can only be used for cursor based pagination
+func (p paginationQueryParams) isCursor() bool {
+	if p.number != "" || p.offset != "" || p.limit != "" {
+		return false
+	}
+
+	return p.size != "" || p.after != "" || p.before != ""
+}
+
 func (p paginationQueryParams) isValid() bool {
+	if p.after != "" || p.before != "" {
+		return false
+	}
+
 	if p.number == "" && p.size == "" && p.offset == "" && p.limit == "" {
 		return false
 	}
@@ -165,20 +198,162 @@ func (p paginationQueryParams) getLinks(r *http.Request, count uint, info inform
 	return
 }
 
+func (p paginationQueryParams) getCursorLinks(r *http.Request, cursors Cursors, info information) jsonapi.Links {
+	result := make(jsonapi.Links)
+
+	prefix := ""
+	baseURL := strings.Trim(info.GetBaseURL(), "/")
+	if baseURL != "" {
+		prefix = baseURL
+	}
+	requestURL := fmt.Sprintf("%s%s", prefix, r.URL.Path)
+
+	if cursors.Next != "" {
+		params := r.URL.Query()
+		params.Del("page[before]")
+		params.Set("page[after]", cursors.Next)
+		result["next"] = jsonapi.Link{Href: fmt.Sprintf("%s?%s", requestURL, encodePaginationQuery(params))}
+	}
+
+	if cursors.Prev != "" {
+		params := r.URL.Query()
+		params.Del("page[after]")
+		params.Set("page[before]", cursors.Prev)
+		result["prev"] = jsonapi.Link{Href: fmt.Sprintf("%s?%s", requestURL, encodePaginationQuery(params))}
+	}
+
+	return result
+}
+
+// encodePaginationQuery encodes the params like url.Values.Encode but keeps the
+// brackets of the page parameter names readable. All other names and all values
+// stay escaped, so opaque cursors survive the round trip.
+func encodePaginationQuery(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var result strings.Builder
+	for _, key := range keys {
+		name := url.QueryEscape(key)
+		if matches := queryPageRegex.FindStringSubmatch(key); matches != nil {
+			name = "page[" + matches[1] + "]"
+		}
+
+		for _, value := range params[key] {
+			if result.Len() > 0 {
+				result.WriteByte('&')
+			}
+			result.WriteString(name + "=" + url.QueryEscape(value))
+		}
+	}
+
+	return result.String()
+}
+
+// validatePagination rejects requests that mix the cursor parameters page[after]
+// and page[before] with number/size or offset/limit pagination, and number/size or
+// offset/limit pagination of sources that only implement CursorPaginatedFindAll.
+// Sources that do not implement PaginatedFindAll or CursorPaginatedFindAll handle
+// the page parameters themselves.
+func (res *resource) validatePagination(r *http.Request) error {
+	_, paginated := res.source.(PaginatedFindAll)
+	_, cursor := res.source.(CursorPaginatedFindAll)
+	if !paginated && !cursor {
+		return nil
+	}
+
+	p := newPaginationQueryParams(r)
+	if p.number == "" && p.offset == "" && p.limit == "" {
+		return nil
+	}
+
+	if p.after != "" || p.before != "" {
+		parameter := "page[after]"
+		if p.after == "" {
+			parameter = "page[before]"
+		}
+
+		return newPaginationError(
+			parameter,
+			fmt.Sprintf(`"%s" can not be combined with number/size or offset/limit pagination`, parameter),
+			"Please use either page[after], page[before] and page[size] or page[number] and page[size] or page[offset] and page[limit]",
+		)
+	}
+
+	if _, findAll := res.source.(FindAll); paginated || findAll {
+		return nil
+	}
+
+	parameter := "page[number]"
+	if p.number == "" {
+		parameter = "page[offset]"
+		if p.offset == "" {
+			parameter = "page[limit]"
+		}
+	}
+
+	return newPaginationError(
+		parameter,
+		fmt.Sprintf(`"%s" is not supported by resource %s`, parameter, res.name),
+		"Please use page[after], page[before] and page[size]",
+	)
+}
+
+func newPaginationError(parameter, title, detail string) HTTPError {
+	httpError := NewHTTPError(nil, "Invalid pagination parameters", http.StatusBadRequest)
+	httpError.Errors = append(httpError.Errors, Error{
+		Status: strconv.Itoa(http.StatusBadRequest),
+		Code:   codeInvalidQueryPagination,
+		Title:  title,
+		Detail: detail,
+		Source: &ErrorSource{
+			Parameter: parameter,
+		},
+	})
+
+	return httpError
+}
+
+// respondWithCursorPage answers with the page of a CursorPaginatedFindAll source if
+// the request asks for cursor pagination, or if the source does not implement FindAll
+// and the request has no page parameters at all. handled is false if the request has
+// to be answered otherwise.
+func (res *resource) respondWithCursorPage(source interface{}, request Request, w http.ResponseWriter, r *http.Request, info information) (handled bool, err error) {
+	cursorSource, ok := source.(CursorPaginatedFindAll)
+	if !ok {
+		return false, nil
+	}
+
+	pagination := newPaginationQueryParams(r)
+	_, findAll := source.(FindAll)
+	if !pagination.isCursor() && !(pagination.isEmpty() && !findAll) {
+		return false, nil
+	}
+
+	cursors, response, err := cursorSource.CursorPaginatedFindAll(request)
+	if err != nil {
+		return true, err
+	}
+
+	return true, res.respondWithPagination(response, info, http.StatusOK, pagination.getCursorLinks(r, cursors, info), w, r)
+}
+
 type notAllowedHandler struct {
 	API *API
 }
 
 func (n notAllowedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	err := NewHTTPError(nil, "Method Not Allowed", http.StatusMethodNotAllowed)
-	w.WriteHeader(http.StatusMethodNotAllowed)
 
-	contentType := defaultContentTypHeader
 	if n.API != nil {
-		contentType = n.API.ContentType
+		n.API.handleError(err, w, r, nil)
+		return
 	}
 
-	handleError(err, w, r, contentType)
+	handleError(err, w, r, defaultContentTypHeader)
 }
 
 type resource struct {
@@ -186,13 +361,105 @@ type resource struct {
 	source       interface{}
 	name         string
 	api          *API
+	middlewares  []Middleware
+	hooks        []interface{}
+	cachePolicy  *CachePolicy
+	config       resourceConfig
 }
 
-// middlewareChain executes the middleeware chain setup
-func (api *API) middlewareChain(c APIContexter, w http.ResponseWriter, r *http.Request) {
-	for _, middleware := range api.middlewares {
-		middleware(c, w, r)
+// middlewareChain wraps the handler with the given middlewares, the first middleware
+// is called first
+func middlewareChain(middlewares []Middleware, handler HandlerFunc) HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
 	}
+
+	return handler
+}
+
+// routeHandlerFunc is implemented by all generated api2go routes
+type routeHandlerFunc func(c APIContexter, w http.ResponseWriter, r *http.Request, params map[string]string, info information) error
+
+// handle registers a generated route at the router. Every request gets a context from the
+// pool and runs through the middleware chain and the content negotiation before the handler
+// is called. Errors of the handler are written inside of the middleware chain.
+func (api *API) handle(route Route, handler routeHandlerFunc) {
+	api.route(route, route.Method != http.MethodOptions, nil, handler)
+}
+
+// handle registers a generated route of the resource. The middlewares of the resource
+// run after the middlewares of the api.
+func (res *resource) handle(route Route, handler routeHandlerFunc) {
+	route.Resource = res.name
+	res.api.route(route, route.Method != http.MethodOptions, res, handler)
+}
+
+// route registers a route like handle, but only negotiates the JSON:API media type if
+// negotiate is set. This is used for routes that do not serve JSON:API documents. If res
+// is not nil, its middlewares are added to the chain.
+func (api *API) route(route Route, negotiate bool, res *resource, handler routeHandlerFunc) {
+	api.routes = append(api.routes, route)
+	api.router.Handle(route.Method, route.Path, func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{}) {
+		if api.compression != nil {
+			var finish func()
+			w, finish = api.compression.wrap(w, r)
+			defer finish()
+		}
+
+		info := api.requestInfo(r)
+		if res != nil {
+			params = res.routeParams(params)
+			if parentIDs := res.parentIDs(params); parentIDs != nil {
+				r = withParentIDs(r, parentIDs)
+				copied := *info
+				copied.parentIDs = parentIDs
+				info = &copied
+			}
+		}
+
+		c := api.contextPool.Get().(APIContexter)
+		c.Reset()
+		if setter, ok := c.(RequestContextSetter); ok {
+			setter.SetRequestContext(r.Context())
+		}
+
+		for key, val := range context {
+			c.Set(key, val)
+		}
+
+		aborted := false
+		chain := func(c APIContexter, w http.ResponseWriter, r *http.Request) {
+			// middlewares may have replaced the request
+			if setter, ok := c.(RequestContextSetter); ok {
+				setter.SetRequestContext(r.Context())
+			}
+
+			var err error
+			if negotiate {
+				r, err = api.negotiate(r)
+			}
+			if err == nil {
+				err = handler(c, w, r, params, *info)
+			}
+
+			if errors.Is(err, errAbortResponse) {
+				aborted = true
+			} else if err != nil {
+				api.handleError(err, w, r, res)
+			}
+		}
+
+		if res != nil {
+			chain = middlewareChain(res.middlewares, chain)
+		}
+		middlewareChain(api.middlewares, chain)(c, w, r)
+
+		api.contextPool.Put(c)
+
+		if aborted && servedByNetHTTP(r) {
+			panic(http.ErrAbortHandler)
+		}
+	})
 }
 
 // allocateContext creates a context for the api.contextPool, saving allocations
@@ -200,7 +467,7 @@ func (api *API) allocateDefaultContext() APIContexter {
 	return &APIContext{}
 }
 
-func (api *API) addResource(prototype jsonapi.MarshalIdentifier, source interface{}) *resource {
+func (api *API) addResource(prototype jsonapi.MarshalIdentifier, source interface{}, options ...ResourceOption) *resource {
 	resourceType := reflect.TypeOf(prototype)
 	if resourceType.Kind() != reflect.Struct && resourceType.Kind() != reflect.Ptr {
 		panic("pass an empty resource struct or a struct pointer to AddResource!")
@@ -224,93 +491,55 @@ func (api *API) addResource(prototype jsonapi.MarshalIdentifier, source interfac
 		name = jsonapi.Jsonify(jsonapi.Pluralize(name))
 	}
 
-	res := resource{
+	if api.resourceByName(name) != nil {
+		panic(fmt.Sprintf("resource %s is already registered, a type can only be added once per API!", name))
+	}
+
+	res := &resource{
 		resourceType: resourceType,
 		name:         name,
 		source:       source,
 		api:          api,
 	}
 
-	requestInfo := func(r *http.Request, api *API) *information {
-		var info *information
-		if resolver, ok := api.info.resolver.(RequestAwareURLResolver); ok {
-			resolver.SetRequest(*r)
-			info = &information{prefix: api.info.prefix, resolver: resolver}
-		} else {
-			info = &api.info
-		}
-
-		return info
-	}
-
-	prefix := strings.Trim(api.info.prefix, "/")
-	baseURL := "/" + name
-	if prefix != "" {
-		baseURL = "/" + prefix + baseURL
+	for _, option := range options {
+		option(&res.config)
 	}
 
-	api.router.Handle("OPTIONS", baseURL, func(w http.ResponseWriter, r *http.Request, _ map[string]string, context map[string]interface{}) {
-		c := api.contextPool.Get().(APIContexter)
-		c.Reset()
-
-		for key, val := range context {
-			c.Set(key, val)
-		}
+	baseURL := api.routePath(res.path())
+	elementURL := baseURL + "/:" + res.idParam()
+	allows := res.config.allows
 
-		api.middlewareChain(c, w, r)
-		w.Header().Set("Allow", strings.Join(getAllowedMethods(source, true), ","))
+	res.handle(Route{Method: http.MethodOptions, Path: baseURL, Kind: RouteOptions}, func(c APIContexter, w http.ResponseWriter, r *http.Request, _ map[string]string, _ information) error {
+		w.Header().Set("Allow", strings.Join(res.allowedMethods(baseURL), ","))
 		w.WriteHeader(http.StatusNoContent)
-		api.contextPool.Put(c)
-	})
-
-	api.router.Handle("GET", baseURL, func(w http.ResponseWriter, r *http.Request, _ map[string]string, context map[string]interface{}) {
-		info := requestInfo(r, api)
-		c := api.contextPool.Get().(APIContexter)
-		c.Reset()
-
-		for key, val := range context {
-			c.Set(key, val)
-		}
-
-		api.middlewareChain(c, w, r)
-
-		err := res.handleIndex(c, w, r, *info)
-		api.contextPool.Put(c)
-		if err != nil {
-			handleError(err, w, r, api.ContentType)
-		}
+		return nil
 	})
 
-	if _, ok := source.(ResourceGetter); ok {
-		api.router.Handle("OPTIONS", baseURL+"/:id", func(w http.ResponseWriter, r *http.Request, _ map[string]string, context map[string]interface{}) {
-			c := api.contextPool.Get().(APIContexter)
-			c.Reset()
+	if allows(OperationList, "") {
+		res.handle(Route{Method: http.MethodGet, Path: baseURL, Kind: RouteIndex}, func(c APIContexter, w http.ResponseWriter, r *http.Request, _ map[string]string, info information) error {
+			return res.handleIndex(c, w, r, info)
+		})
+	}
 
-			for key, val := range context {
-				c.Set(key, val)
-			}
+	_, getter := source.(ResourceGetter)
+	_, deleter := source.(ResourceDeleter)
+	_, updater := source.(ResourceUpdater)
+	getter = getter && allows(OperationRead, "")
+	deleter = deleter && allows(OperationDelete, "")
+	updater = updater && allows(OperationUpdate, "")
 
-			api.middlewareChain(c, w, r)
-			w.Header().Set("Allow", strings.Join(getAllowedMethods(source, false), ","))
+	if getter || deleter || updater {
+		res.handle(Route{Method: http.MethodOptions, Path: elementURL, Kind: RouteOptions}, func(c APIContexter, w http.ResponseWriter, r *http.Request, _ map[string]string, _ information) error {
+			w.Header().Set("Allow", strings.Join(res.allowedMethods(elementURL), ","))
 			w.WriteHeader(http.StatusNoContent)
-			api.contextPool.Put(c)
+			return nil
 		})
+	}
 
-		api.router.Handle("GET", baseURL+"/:id", func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{}) {
-			info := requestInfo(r, api)
-			c := api.contextPool.Get().(APIContexter)
-			c.Reset()
-
-			for key, val := range context {
-				c.Set(key, val)
-			}
-
-			api.middlewareChain(c, w, r)
-			err := res.handleRead(c, w, r, params, *info)
-			api.contextPool.Put(c)
-			if err != nil {
-				handleError(err, w, r, api.ContentType)
-			}
+	if getter {
+		res.handle(Route{Method: http.MethodGet, Path: elementURL, Kind: RouteRead}, func(c APIContexter, w http.ResponseWriter, r *http.Request, params map[string]string, info information) error {
+			return res.handleRead(c, w, r, params, info)
 		})
 	}
 
@@ -319,184 +548,88 @@ func (api *API) addResource(prototype jsonapi.MarshalIdentifier, source interfac
 	if ok {
 		relations := casted.GetReferences()
 		for _, relation := range relations {
-			api.router.Handle("GET", baseURL+"/:id/relationships/"+relation.Name, func(relation jsonapi.Reference) routing.HandlerFunc {
-				return func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{}) {
-					info := requestInfo(r, api)
-					c := api.contextPool.Get().(APIContexter)
-					c.Reset()
-
-					for key, val := range context {
-						c.Set(key, val)
-					}
+			relationshipURL := elementURL + "/relationships/" + res.config.relationshipPath(relation.Name)
+			relatedURL := elementURL + "/" + res.config.relationshipPath(relation.Name)
 
-					api.middlewareChain(c, w, r)
-					err := res.handleReadRelation(c, w, r, params, *info, relation)
-					api.contextPool.Put(c)
-					if err != nil {
-						handleError(err, w, r, api.ContentType)
-					}
-				}
-			}(relation))
+			if allows(OperationReadRelationship, relation.Name) {
+				res.handle(Route{Method: http.MethodGet, Path: relationshipURL, Kind: RouteRelationship, Relationship: relation.Name}, func(c APIContexter, w http.ResponseWriter, r *http.Request, params map[string]string, info information) error {
+					return res.handleReadRelation(c, w, r, params, info, relation)
+				})
+			}
 
-			api.router.Handle("GET", baseURL+"/:id/"+relation.Name, func(relation jsonapi.Reference) routing.HandlerFunc {
-				return func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{}) {
-					info := requestInfo(r, api)
-					c := api.contextPool.Get().(APIContexter)
-					c.Reset()
+			if allows(OperationReadRelated, relation.Name) {
+				res.handle(Route{Method: http.MethodGet, Path: relatedURL, Kind: RouteRelated, Relationship: relation.Name}, func(c APIContexter, w http.ResponseWriter, r *http.Request, params map[string]string, info information) error {
+					return res.handleLinked(c, api, w, r, params, relation, info)
+				})
+			}
 
-					for key, val := range context {
-						c.Set(key, val)
-					}
+			if allows(OperationReplaceRelationship, relation.Name) {
+				res.handle(Route{Method: http.MethodPatch, Path: relationshipURL, Kind: RouteRelationship, Relationship: relation.Name}, func(c APIContexter, w http.ResponseWriter, r *http.Request, params map[string]string, _ information) error {
+					return res.handleReplaceRelation(c, w, r, params, relation)
+				})
+			}
 
-					api.middlewareChain(c, w, r)
-					err := res.handleLinked(c, api, w, r, params, relation, *info)
-					api.contextPool.Put(c)
-					if err != nil {
-						handleError(err, w, r, api.ContentType)
-					}
+			if _, ok := ptrPrototype.(jsonapi.EditToManyRelations); ok && relation.Name == jsonapi.Pluralize(relation.Name) {
+				// generate additional routes to manipulate to-many relationships
+				if allows(OperationAddToRelationship, relation.Name) {
+					res.handle(Route{Method: http.MethodPost, Path: relationshipURL, Kind: RouteRelationship, Relationship: relation.Name}, func(c APIContexter, w http.ResponseWriter, r *http.Request, params map[string]string, _ information) error {
+						return res.handleAddToManyRelation(c, w, r, params, relation)
+					})
 				}
-			}(relation))
-
-			api.router.Handle("PATCH", baseURL+"/:id/relationships/"+relation.Name, func(relation jsonapi.Reference) routing.HandlerFunc {
-				return func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{}) {
-					c := api.contextPool.Get().(APIContexter)
-					c.Reset()
 
-					for key, val := range context {
-						c.Set(key, val)
-					}
-
-					api.middlewareChain(c, w, r)
-					err := res.handleReplaceRelation(c, w, r, params, relation)
-					api.contextPool.Put(c)
-					if err != nil {
-						handleError(err, w, r, api.ContentType)
-					}
+				if allows(OperationRemoveFromRelationship, relation.Name) {
+					res.handle(Route{Method: http.MethodDelete, Path: relationshipURL, Kind: RouteRelationship, Relationship: relation.Name}, func(c APIContexter, w http.ResponseWriter, r *http.Request, params map[string]string, _ information) error {
+						return res.handleDeleteToManyRelation(c, w, r, params, relation)
+					})
 				}
-			}(relation))
-
-			if _, ok := ptrPrototype.(jsonapi.EditToManyRelations); ok && relation.Name == jsonapi.Pluralize(relation.Name) {
-				// generate additional routes to manipulate to-many relationships
-				api.router.Handle("POST", baseURL+"/:id/relationships/"+relation.Name, func(relation jsonapi.Reference) routing.HandlerFunc {
-					return func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{}) {
-						c := api.contextPool.Get().(APIContexter)
-						c.Reset()
-
-						for key, val := range context {
-							c.Set(key, val)
-						}
-
-						api.middlewareChain(c, w, r)
-						err := res.handleAddToManyRelation(c, w, r, params, relation)
-						api.contextPool.Put(c)
-						if err != nil {
-							handleError(err, w, r, api.ContentType)
-						}
-					}
-				}(relation))
-
-				api.router.Handle("DELETE", baseURL+"/:id/relationships/"+relation.Name, func(relation jsonapi.Reference) routing.HandlerFunc {
-					return func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{}) {
-						c := api.contextPool.Get().(APIContexter)
-						c.Reset()
-
-						for key, val := range context {
-							c.Set(key, val)
-						}
-
-						api.middlewareChain(c, w, r)
-						err := res.handleDeleteToManyRelation(c, w, r, params, relation)
-						api.contextPool.Put(c)
-						if err != nil {
-							handleError(err, w, r, api.ContentType)
-						}
-					}
-				}(relation))
 			}
 		}
 	}
 
-	if _, ok := source.(ResourceCreator); ok {
-		api.router.Handle("POST", baseURL, func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{}) {
-			info := requestInfo(r, api)
-			c := api.contextPool.Get().(APIContexter)
-			c.Reset()
-
-			for key, val := range context {
-				c.Set(key, val)
-			}
-
-			api.middlewareChain(c, w, r)
-			err := res.handleCreate(c, w, r, info.prefix, *info)
-			api.contextPool.Put(c)
-			if err != nil {
-				handleError(err, w, r, api.ContentType)
-			}
+	if _, ok := source.(ResourceCreator); ok && allows(OperationCreate, "") {
+		res.handle(Route{Method: http.MethodPost, Path: baseURL, Kind: RouteCreate}, func(c APIContexter, w http.ResponseWriter, r *http.Request, _ map[string]string, info information) error {
+			return res.handleCreate(c, w, r, info.prefix, info)
 		})
 	}
 
-	if _, ok := source.(ResourceDeleter); ok {
-		api.router.Handle("DELETE", baseURL+"/:id", func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{}) {
-			c := api.contextPool.Get().(APIContexter)
-			c.Reset()
-
-			for key, val := range context {
-				c.Set(key, val)
-			}
-
-			api.middlewareChain(c, w, r)
-			err := res.handleDelete(c, w, r, params)
-			api.contextPool.Put(c)
-			if err != nil {
-				handleError(err, w, r, api.ContentType)
-			}
+	if deleter {
+		res.handle(Route{Method: http.MethodDelete, Path: elementURL, Kind: RouteDelete}, func(c APIContexter, w http.ResponseWriter, r *http.Request, params map[string]string, _ information) error {
+			return res.handleDelete(c, w, r, params)
 		})
 	}
 
-	if _, ok := source.(ResourceUpdater); ok {
-		api.router.Handle("PATCH", baseURL+"/:id", func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{}) {
-			info := requestInfo(r, api)
-			c := api.contextPool.Get().(APIContexter)
-			c.Reset()
-
-			for key, val := range context {
-				c.Set(key, val)
-			}
-
-			api.middlewareChain(c, w, r)
-			err := res.handleUpdate(c, w, r, params, *info)
-			api.contextPool.Put(c)
-			if err != nil {
-				handleError(err, w, r, api.ContentType)
-			}
+	if updater {
+		res.handle(Route{Method: http.MethodPatch, Path: elementURL, Kind: RouteUpdate}, func(c APIContexter, w http.ResponseWriter, r *http.Request, params map[string]string, info information) error {
+			return res.handleUpdate(c, w, r, params, info)
 		})
 	}
 
 	api.resources = append(api.resources, res)
 
-	return &res
+	return res
 }
 
-func getAllowedMethods(source interface{}, collection bool) []string {
-	result := []string{http.MethodOptions}
-
-	if _, ok := source.(ResourceGetter); ok {
-		result = append(result, http.MethodGet)
-	}
-
-	if _, ok := source.(ResourceUpdater); ok {
-		result = append(result, http.MethodPatch)
+// routePath returns the route of the given path below the api prefix
+func (api *API) routePath(path string) string {
+	prefix := strings.Trim(api.info.prefix, "/")
+	if prefix != "" {
+		return "/" + prefix + "/" + path
 	}
 
-	if _, ok := source.(ResourceDeleter); ok && !collection {
-		result = append(result, http.MethodDelete)
-	}
+	return "/" + path
+}
 
-	if _, ok := source.(ResourceCreator); ok && collection {
-		result = append(result, http.MethodPost)
+// requestInfo returns the information used to generate urls for the request
+func (api *API) requestInfo(r *http.Request) *information {
+	var info *information
+	if resolver, ok := api.info.resolver.(RequestAwareURLResolver); ok {
+		resolver.SetRequest(*r)
+		info = &information{prefix: api.info.prefix, resolver: resolver, api: api}
+	} else {
+		info = &api.info
 	}
 
-	return result
+	return info
 }
 
 func buildRequest(c APIContexter, r *http.Request) Request {
@@ -512,30 +645,78 @@ func buildRequest(c APIContexter, r *http.Request) Request {
 	}
 	req.Pagination = pagination
 	req.QueryParams = params
+	req.ParentIDs = getParentIDs(r)
+	req.Include = getIncludePaths(r)
+	req.Sort = parseSortFields(r)
+	filters := parseFilters(r)
+	req.Filters = filters.Filters
+	req.FilterGroups = filters.Groups
+	negotiated := getNegotiation(r)
+	req.Extensions = negotiated.extensions
+	req.Profiles = negotiated.profiles
 	req.Header = r.Header
 	req.Context = c
 	return req
 }
 
 func (res *resource) marshalResponse(resp interface{}, w http.ResponseWriter, status int, r *http.Request) error {
-	filtered, err := filterSparseFields(resp, r)
+	result, err := encodeResponse(resp, r)
 	if err != nil {
 		return err
 	}
-	result, err := json.Marshal(filtered)
+	writeResult(w, result, status, responseContentType(r, res.api.ContentType))
+	return nil
+}
+
+// respondWithDocument writes the document of obj like marshalResponse. The version and
+// cache headers of obj are only set once the document could be encoded, so error
+// responses do not carry them.
+func (res *resource) respondWithDocument(obj Responder, document *jsonapi.Document, w http.ResponseWriter, status int, r *http.Request) error {
+	result, err := encodeResponse(document, r)
 	if err != nil {
 		return err
 	}
-	writeResult(w, result, status, res.api.ContentType)
+
+	setVersionHeaders(w, obj.Result())
+	res.setCacheHeaders(w, r, obj)
+	writeResult(w, result, status, responseContentType(r, res.api.ContentType))
 	return nil
 }
 
+// encodeResponse applies the sparse fieldsets of the request to resp and encodes it
+func encodeResponse(resp interface{}, r *http.Request) ([]byte, error) {
+	filtered, err := filterSparseFields(resp, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(filtered)
+}
+
 func (res *resource) handleIndex(c APIContexter, w http.ResponseWriter, r *http.Request, info information) error {
+	if err := res.validateInclude(getIncludePaths(r)); err != nil {
+		return err
+	}
+
+	if err := res.validateSort(parseSortFields(r)); err != nil {
+		return err
+	}
+
+	if err := res.validateFilters(r.URL.Query()); err != nil {
+		return err
+	}
+
+	if err := res.validatePagination(r); err != nil {
+		return err
+	}
+
+	request := buildRequest(c, r)
+
 	if source, ok := res.source.(PaginatedFindAll); ok {
 		pagination := newPaginationQueryParams(r)
 
 		if pagination.isValid() {
-			count, response, err := source.PaginatedFindAll(buildRequest(c, r))
+			count, response, err := source.PaginatedFindAll(request)
 			if err != nil {
 				return err
 			}
@@ -549,12 +730,16 @@ func (res *resource) handleIndex(c APIContexter, w http.ResponseWriter, r *http.
 		}
 	}
 
+	if handled, err := res.respondWithCursorPage(res.source, request, w, r, info); handled {
+		return err
+	}
+
 	source, ok := res.source.(FindAll)
 	if !ok {
 		return NewHTTPError(nil, "Resource does not implement the FindAll interface", http.StatusNotFound)
 	}
 
-	response, err := source.FindAll(buildRequest(c, r))
+	response, err := source.FindAll(request)
 	if err != nil {
 		return err
 	}
@@ -569,6 +754,10 @@ func (res *resource) handleRead(c APIContexter, w http.ResponseWriter, r *http.R
 		return fmt.Errorf("Resource %s does not implement the ResourceGetter interface", res.name)
 	}
 
+	if err := res.validateInclude(getIncludePaths(r)); err != nil {
+		return err
+	}
+
 	id := params["id"]
 
 	response, err := source.FindOne(id, buildRequest(c, r))
@@ -577,6 +766,13 @@ func (res *resource) handleRead(c APIContexter, w http.ResponseWriter, r *http.R
 		return err
 	}
 
+	if res.notModified(r, response.Result()) {
+		setVersionHeaders(w, response.Result())
+		res.setCacheHeaders(w, r, response)
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
 	return res.respondWith(response, info, http.StatusOK, w, r)
 }
 
@@ -609,7 +805,14 @@ func (res *resource) handleReadRelation(c APIContexter, w http.ResponseWriter, r
 		rel.Meta = meta
 	}
 
-	return res.marshalResponse(rel, w, http.StatusOK, r)
+	result, err := encodeResponse(rel, r)
+	if err != nil {
+		return err
+	}
+
+	res.setCacheHeaders(w, r, obj)
+	writeResult(w, result, http.StatusOK, responseContentType(r, res.api.ContentType))
+	return nil
 }
 
 // try to find the referenced resource and call the findAll Method with referencing resource id as param
@@ -617,6 +820,22 @@ func (res *resource) handleLinked(c APIContexter, api *API, w http.ResponseWrite
 	id := params["id"]
 	for _, resource := range api.resources {
 		if resource.name == linked.Type {
+			if err := resource.validateInclude(getIncludePaths(r)); err != nil {
+				return err
+			}
+
+			if err := resource.validateSort(parseSortFields(r)); err != nil {
+				return err
+			}
+
+			if err := resource.validateFilters(r.URL.Query()); err != nil {
+				return err
+			}
+
+			if err := resource.validatePagination(r); err != nil {
+				return err
+			}
+
 			request := buildRequest(c, r)
 			request.QueryParams[res.name+"ID"] = []string{id}
 			request.QueryParams[res.name+"Name"] = []string{linked.Name}
@@ -636,10 +855,14 @@ func (res *resource) handleLinked(c APIContexter, api *API, w http.ResponseWrite
 						return err
 					}
 
-					return res.respondWithPagination(response, info, http.StatusOK, paginationLinks, w, r)
+					return resource.respondWithPagination(response, info, http.StatusOK, paginationLinks, w, r)
 				}
 			}
 
+			if handled, err := resource.respondWithCursorPage(resource.source, request, w, r, info); handled {
+				return err
+			}
+
 			source, ok := resource.source.(FindAll)
 			if !ok {
 				return NewHTTPError(nil, "Resource does not implement the FindAll interface", http.StatusNotFound)
@@ -649,7 +872,7 @@ func (res *resource) handleLinked(c APIContexter, api *API, w http.ResponseWrite
 			if err != nil {
 				return err
 			}
-			return res.respondWith(obj, info, http.StatusOK, w, r)
+			return resource.respondWith(obj, info, http.StatusOK, w, r)
 		}
 	}
 
@@ -667,38 +890,16 @@ func (res *resource) handleCreate(c APIContexter, w http.ResponseWriter, r *http
 		return fmt.Errorf("Resource %s does not implement the ResourceCreator interface", res.name)
 	}
 
-	ctx, err := unmarshalRequest(r)
-	if err != nil {
+	if err := res.validateInclude(getIncludePaths(r)); err != nil {
 		return err
 	}
 
-	// Ok this is weird again, but reflect.New produces a pointer, so we need the pure type without pointer,
-	// otherwise we would have a pointer pointer type that we don't want.
-	resourceType := res.resourceType
-	if resourceType.Kind() == reflect.Ptr {
-		resourceType = resourceType.Elem()
-	}
-	newObj := reflect.New(resourceType).Interface()
-
-	// Call InitializeObject if available to allow implementers change the object
-	// before calling Unmarshal.
-	if initSource, ok := source.(ObjectInitializer); ok {
-		initSource.InitializeObject(newObj)
-	}
-
-	err = jsonapi.Unmarshal(ctx, newObj)
+	ctx, err := unmarshalRequest(r)
 	if err != nil {
-		return NewHTTPError(nil, err.Error(), http.StatusNotAcceptable)
+		return err
 	}
 
-	var response Responder
-
-	if res.resourceType.Kind() == reflect.Struct {
-		// we have to dereference the pointer if user wants to use non pointer values
-		response, err = source.Create(reflect.ValueOf(newObj).Elem().Interface(), buildRequest(c, r))
-	} else {
-		response, err = source.Create(newObj, buildRequest(c, r))
-	}
+	response, err := res.create(source, c, r, ctx)
 	if err != nil {
 		return err
 	}
@@ -709,10 +910,13 @@ func (res *resource) handleCreate(c APIContexter, w http.ResponseWriter, r *http
 		return fmt.Errorf("Expected one newly created object by resource %s", res.name)
 	}
 
-	if len(prefix) > 0 {
-		w.Header().Set("Location", "/"+prefix+"/"+res.name+"/"+result.GetID().ID)
-	} else {
-		w.Header().Set("Location", "/"+res.name+"/"+result.GetID().ID)
+	// the path is empty if the ids of the parents are unknown
+	if path := info.GetResourcePath(res.name, result); path != "" {
+		if len(prefix) > 0 {
+			w.Header().Set("Location", "/"+prefix+"/"+path+"/"+result.GetID().ID)
+		} else {
+			w.Header().Set("Location", "/"+path+"/"+result.GetID().ID)
+		}
 	}
 
 	// handle 200 status codes
@@ -730,46 +934,79 @@ func (res *resource) handleCreate(c APIContexter, w http.ResponseWriter, r *http
 	}
 }
 
-func (res *resource) handleUpdate(c APIContexter, w http.ResponseWriter, r *http.Request, params map[string]string, info information) error {
-	source, ok := res.source.(ResourceUpdater)
+// create unmarshals the document in body into a new object and passes it to the source
+func (res *resource) create(source ResourceCreator, c APIContexter, r *http.Request, body []byte) (Responder, error) {
+	// Ok this is weird again, but reflect.New produces a pointer, so we need the pure type without pointer,
+	// otherwise we would have a pointer pointer type that we don't want.
+	resourceType := res.resourceType
+	if resourceType.Kind() == reflect.Ptr {
+		resourceType = resourceType.Elem()
+	}
+	newObj := reflect.New(resourceType).Interface()
 
-	if !ok {
-		return fmt.Errorf("Resource %s does not implement the ResourceUpdater interface", res.name)
+	// Call InitializeObject if available to allow implementers change the object
+	// before calling Unmarshal.
+	if initSource, ok := source.(ObjectInitializer); ok {
+		initSource.InitializeObject(newObj)
 	}
 
-	id := params["id"]
-	obj, err := source.FindOne(id, buildRequest(c, r))
+	err := jsonapi.Unmarshal(body, newObj)
 	if err != nil {
-		return err
+		return nil, unmarshalError(err)
 	}
 
-	ctx, err := unmarshalRequest(r)
+	req := buildRequest(c, r)
+	if err := validate(newObj, req); err != nil {
+		return nil, err
+	}
+
+	err = res.runHooks(func(hook interface{}) error {
+		if before, ok := hook.(BeforeCreate); ok {
+			return before.BeforeCreate(newObj, req)
+		}
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// we have to make the Result to a pointer to unmarshal into it
-	updatingObj := reflect.ValueOf(obj.Result())
-	if updatingObj.Kind() == reflect.Struct {
-		updatingObjPtr := reflect.New(reflect.TypeOf(obj.Result()))
-		updatingObjPtr.Elem().Set(updatingObj)
-		err = jsonapi.Unmarshal(ctx, updatingObjPtr.Interface())
-		updatingObj = updatingObjPtr.Elem()
-	} else {
-		err = jsonapi.Unmarshal(ctx, updatingObj.Interface())
+	obj := newObj
+	if res.resourceType.Kind() == reflect.Struct {
+		// we have to dereference the pointer if user wants to use non pointer values
+		obj = reflect.ValueOf(newObj).Elem().Interface()
 	}
+
+	response, err := source.Create(obj, req)
 	if err != nil {
-		return NewHTTPError(nil, err.Error(), http.StatusNotAcceptable)
+		return nil, err
 	}
 
-	identifiable, ok := updatingObj.Interface().(jsonapi.MarshalIdentifier)
-	if !ok || identifiable.GetID().ID != id {
-		conflictError := errors.New("id in the resource does not match servers endpoint")
-		return NewHTTPError(conflictError, conflictError.Error(), http.StatusConflict)
+	err = res.runHooks(func(hook interface{}) error {
+		if after, ok := hook.(AfterCreate); ok {
+			return after.AfterCreate(hookResult(response, obj), req)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := source.Update(updatingObj.Interface(), buildRequest(c, r))
+	return response, nil
+}
+
+func (res *resource) handleUpdate(c APIContexter, w http.ResponseWriter, r *http.Request, params map[string]string, info information) error {
+	source, ok := res.source.(ResourceUpdater)
+
+	if !ok {
+		return fmt.Errorf("Resource %s does not implement the ResourceUpdater interface", res.name)
+	}
 
+	if err := res.validateInclude(getIncludePaths(r)); err != nil {
+		return err
+	}
+
+	id := params["id"]
+	response, err := res.update(source, c, r, id, nil)
 	if err != nil {
 		return err
 	}
@@ -802,6 +1039,55 @@ func (res *resource) handleUpdate(c APIContexter, w http.ResponseWriter, r *http
 	}
 }
 
+// update loads the object with the given id, unmarshals the request document into it
+// and passes it to the source. If body is nil, the request body is read after FindOne.
+func (res *resource) update(source ResourceUpdater, c APIContexter, r *http.Request, id string, body []byte) (Responder, error) {
+	req := buildRequest(c, r)
+	obj, err := source.FindOne(id, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := res.checkPreconditions(r, obj.Result()); err != nil {
+		return nil, err
+	}
+	oldObj := copyObject(obj.Result())
+
+	if body == nil {
+		body, err = unmarshalRequest(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// we have to make the Result to a pointer to unmarshal into it
+	updatingObj := reflect.ValueOf(obj.Result())
+	newObj := obj.Result()
+	if updatingObj.Kind() == reflect.Struct {
+		updatingObjPtr := reflect.New(reflect.TypeOf(obj.Result()))
+		updatingObjPtr.Elem().Set(updatingObj)
+		err = jsonapi.Unmarshal(body, updatingObjPtr.Interface())
+		updatingObj = updatingObjPtr.Elem()
+		newObj = updatingObjPtr.Interface()
+	} else {
+		err = jsonapi.Unmarshal(body, updatingObj.Interface())
+	}
+	if err != nil {
+		return nil, unmarshalError(err)
+	}
+
+	identifiable, ok := updatingObj.Interface().(jsonapi.MarshalIdentifier)
+	if !ok || identifiable.GetID().ID != id {
+		conflictError := errors.New("id in the resource does not match servers endpoint")
+		return nil, NewHTTPError(conflictError, conflictError.Error(), http.StatusConflict)
+	}
+
+	if err := validate(newObj, req); err != nil {
+		return nil, err
+	}
+
+	return res.runUpdate(source, req, oldObj, newObj, updatingObj.Kind() == reflect.Struct)
+}
+
 func (res *resource) handleReplaceRelation(c APIContexter, w http.ResponseWriter, r *http.Request, params map[string]string, relation jsonapi.Reference) error {
 	source, ok := res.source.(ResourceUpdater)
 
@@ -809,32 +1095,34 @@ func (res *resource) handleReplaceRelation(c APIContexter, w http.ResponseWriter
 		return fmt.Errorf("Resource %s does not implement the ResourceUpdater interface", res.name)
 	}
 
-	var (
-		err     error
-		editObj interface{}
-	)
-
-	id := params["id"]
-
-	response, err := source.FindOne(id, buildRequest(c, r))
+	data, err := unmarshalRelationshipData(r)
 	if err != nil {
 		return err
 	}
 
-	body, err := unmarshalRequest(r)
+	err = res.replaceRelation(source, c, r, params["id"], relation, data)
 	if err != nil {
 		return err
 	}
 
-	inc := map[string]interface{}{}
-	err = json.Unmarshal(body, &inc)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// replaceRelation replaces the relationship of the object with the given id with the
+// resource identifier object(s) in data
+func (res *resource) replaceRelation(source ResourceUpdater, c APIContexter, r *http.Request, id string, relation jsonapi.Reference, data interface{}) error {
+	var editObj interface{}
+
+	req := buildRequest(c, r)
+	response, err := source.FindOne(id, req)
 	if err != nil {
 		return err
 	}
-	data, ok := inc["data"]
-	if !ok {
-		return errors.New("Invalid object. Need a \"data\" object")
+	if err := res.checkPreconditions(r, response.Result()); err != nil {
+		return err
 	}
+	oldObj := copyObject(response.Result())
 
 	resType := reflect.TypeOf(response.Result()).Kind()
 	if resType == reflect.Struct {
@@ -848,13 +1136,8 @@ func (res *resource) handleReplaceRelation(c APIContexter, w http.ResponseWriter
 		return err
 	}
 
-	if resType == reflect.Struct {
-		_, err = source.Update(reflect.ValueOf(editObj).Elem().Interface(), buildRequest(c, r))
-	} else {
-		_, err = source.Update(editObj, buildRequest(c, r))
-	}
+	_, err = res.runUpdate(source, req, oldObj, editObj, resType == reflect.Struct)
 
-	w.WriteHeader(http.StatusNoContent)
 	return err
 }
 
@@ -865,78 +1148,18 @@ func (res *resource) handleAddToManyRelation(c APIContexter, w http.ResponseWrit
 		return fmt.Errorf("Resource %s does not implement the ResourceUpdater interface", res.name)
 	}
 
-	var (
-		err     error
-		editObj interface{}
-	)
-
-	id := params["id"]
-
-	response, err := source.FindOne(id, buildRequest(c, r))
-	if err != nil {
-		return err
-	}
-
-	body, err := unmarshalRequest(r)
-	if err != nil {
-		return err
-	}
-	inc := map[string]interface{}{}
-	err = json.Unmarshal(body, &inc)
+	data, err := unmarshalRelationshipData(r)
 	if err != nil {
 		return err
 	}
 
-	data, ok := inc["data"]
-	if !ok {
-		return errors.New("Invalid object. Need a \"data\" object")
-	}
-
-	newRels, ok := data.([]interface{})
-	if !ok {
-		return fmt.Errorf("Data must be an array with \"id\" and \"type\" field to add new to-many relationships")
-	}
-
-	newIDs := []string{}
-
-	for _, newRel := range newRels {
-		casted, ok := newRel.(map[string]interface{})
-		if !ok {
-			return errors.New("entry in data object invalid")
-		}
-		newID, ok := casted["id"].(string)
-		if !ok {
-			return errors.New("no id field found inside data object")
-		}
-
-		newIDs = append(newIDs, newID)
-	}
-
-	resType := reflect.TypeOf(response.Result()).Kind()
-	if resType == reflect.Struct {
-		editObj = getPointerToStruct(response.Result())
-	} else {
-		editObj = response.Result()
-	}
-
-	targetObj, ok := editObj.(jsonapi.EditToManyRelations)
-	if !ok {
-		return errors.New("target struct must implement jsonapi.EditToManyRelations")
-	}
-	err = targetObj.AddToManyIDs(relation.Name, newIDs)
+	err = res.editToManyRelation(source, c, r, params["id"], relation, data, true)
 	if err != nil {
 		return err
 	}
 
-	if resType == reflect.Struct {
-		_, err = source.Update(reflect.ValueOf(targetObj).Elem().Interface(), buildRequest(c, r))
-	} else {
-		_, err = source.Update(targetObj, buildRequest(c, r))
-	}
-
 	w.WriteHeader(http.StatusNoContent)
-
-	return err
+	return nil
 }
 
 func (res *resource) handleDeleteToManyRelation(c APIContexter, w http.ResponseWriter, r *http.Request, params map[string]string, relation jsonapi.Reference) error {
@@ -946,52 +1169,54 @@ func (res *resource) handleDeleteToManyRelation(c APIContexter, w http.ResponseW
 		return fmt.Errorf("Resource %s does not implement the ResourceUpdater interface", res.name)
 	}
 
-	var (
-		err     error
-		editObj interface{}
-	)
-
-	id := params["id"]
-
-	response, err := source.FindOne(id, buildRequest(c, r))
+	data, err := unmarshalRelationshipData(r)
 	if err != nil {
 		return err
 	}
 
-	body, err := unmarshalRequest(r)
+	err = res.editToManyRelation(source, c, r, params["id"], relation, data, false)
 	if err != nil {
 		return err
 	}
 
-	inc := map[string]interface{}{}
-	err = json.Unmarshal(body, &inc)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// editToManyRelation adds (or deletes if add is false) the resource identifier objects in data
+// to the to-many relationship of the object with the given id
+func (res *resource) editToManyRelation(source ResourceUpdater, c APIContexter, r *http.Request, id string, relation jsonapi.Reference, data interface{}, add bool) error {
+	var editObj interface{}
+
+	req := buildRequest(c, r)
+	response, err := source.FindOne(id, req)
 	if err != nil {
 		return err
 	}
-
-	data, ok := inc["data"]
-	if !ok {
-		return errors.New("Invalid object. Need a \"data\" object")
+	if err := res.checkPreconditions(r, response.Result()); err != nil {
+		return err
 	}
+	oldObj := copyObject(response.Result())
 
 	newRels, ok := data.([]interface{})
 	if !ok {
-		return fmt.Errorf("Data must be an array with \"id\" and \"type\" field to add new to-many relationships")
+		return relationshipDataError("/data", relation.Name, errors.New("Data must be an array with \"id\" and \"type\" field to add new to-many relationships"))
 	}
 
-	obsoleteIDs := []string{}
+	IDs := []string{}
 
-	for _, newRel := range newRels {
+	for i, newRel := range newRels {
+		pointer := fmt.Sprintf("/data/%d", i)
 		casted, ok := newRel.(map[string]interface{})
 		if !ok {
-			return errors.New("entry in data object invalid")
+			return relationshipDataError(pointer, relation.Name, errors.New("entry in data object invalid"))
 		}
-		obsoleteID, ok := casted["id"].(string)
+		newID, ok := casted["id"].(string)
 		if !ok {
-			return errors.New("no id field found inside data object")
+			return relationshipDataError(pointer, relation.Name, errors.New("no id field found inside data object"))
 		}
 
-		obsoleteIDs = append(obsoleteIDs, obsoleteID)
+		IDs = append(IDs, newID)
 	}
 
 	resType := reflect.TypeOf(response.Result()).Kind()
@@ -1005,22 +1230,54 @@ func (res *resource) handleDeleteToManyRelation(c APIContexter, w http.ResponseW
 	if !ok {
 		return errors.New("target struct must implement jsonapi.EditToManyRelations")
 	}
-	err = targetObj.DeleteToManyIDs(relation.Name, obsoleteIDs)
-	if err != nil {
-		return err
-	}
 
-	if resType == reflect.Struct {
-		_, err = source.Update(reflect.ValueOf(targetObj).Elem().Interface(), buildRequest(c, r))
+	if add {
+		err = targetObj.AddToManyIDs(relation.Name, IDs)
 	} else {
-		_, err = source.Update(targetObj, buildRequest(c, r))
+		err = targetObj.DeleteToManyIDs(relation.Name, IDs)
+	}
+	if err != nil {
+		return relationshipDataError("/data", relation.Name, err)
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	_, err = res.runUpdate(source, req, oldObj, targetObj, resType == reflect.Struct)
 
 	return err
 }
 
+// unmarshalRelationshipData returns the content of the "data" member of the request document
+func unmarshalRelationshipData(r *http.Request) (interface{}, error) {
+	body, err := unmarshalRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	inc := map[string]interface{}{}
+	err = json.Unmarshal(body, &inc)
+	if syntaxErr, ok := err.(*json.SyntaxError); ok {
+		return nil, unmarshalError(&jsonapi.SyntaxError{Offset: syntaxErr.Offset, Err: syntaxErr})
+	}
+	if err != nil {
+		return nil, unmarshalError(&jsonapi.DocumentError{Err: err})
+	}
+
+	data, ok := inc["data"]
+	if !ok {
+		return nil, unmarshalError(&jsonapi.DocumentError{
+			Pointer: "/data",
+			Err:     errors.New("Invalid object. Need a \"data\" object"),
+		})
+	}
+
+	return data, nil
+}
+
+// relationshipDataError returns a 400 Bad Request error for the relationship document
+// member at pointer
+func relationshipDataError(pointer, linkName string, err error) error {
+	return unmarshalError(&jsonapi.RelationshipError{Pointer: pointer, Relationship: linkName, Err: err})
+}
+
 // returns a pointer to an interface{} struct
 func getPointerToStruct(oldObj interface{}) interface{} {
 	resType := reflect.TypeOf(oldObj)
@@ -1037,7 +1294,7 @@ func (res *resource) handleDelete(c APIContexter, w http.ResponseWriter, r *http
 	}
 
 	id := params["id"]
-	response, err := source.Delete(id, buildRequest(c, r))
+	response, err := res.delete(source, c, r, id)
 	if err != nil {
 		return err
 	}
@@ -1067,7 +1324,18 @@ func writeResult(w http.ResponseWriter, data []byte, status int, contentType str
 }
 
 func (res *resource) respondWith(obj Responder, info information, status int, w http.ResponseWriter, r *http.Request) error {
-	data, err := jsonapi.MarshalToStruct(obj.Result(), info)
+	var links jsonapi.Links
+	if objWithLinks, ok := obj.(LinksResponder); ok {
+		baseURL := strings.Trim(info.GetBaseURL(), "/")
+		requestURL := fmt.Sprintf("%s%s", baseURL, r.URL.Path)
+		links = objWithLinks.Links(r, requestURL)
+	}
+
+	if streamer, ok := obj.(Streamer); ok {
+		return res.stream(streamer, obj, info, status, links, w, r)
+	}
+
+	data, err := jsonapi.MarshalToStructWithIncludes(obj.Result(), info, getIncludePaths(r))
 	if err != nil {
 		return err
 	}
@@ -1077,20 +1345,19 @@ func (res *resource) respondWith(obj Responder, info information, status int, w
 		data.Meta = meta
 	}
 
-	if objWithLinks, ok := obj.(LinksResponder); ok {
-		baseURL := strings.Trim(info.GetBaseURL(), "/")
-		requestURL := fmt.Sprintf("%s%s", baseURL, r.URL.Path)
-		links := objWithLinks.Links(r, requestURL)
-		if len(links) > 0 {
-			data.Links = links
-		}
+	if len(links) > 0 {
+		data.Links = links
 	}
 
-	return res.marshalResponse(data, w, status, r)
+	return res.respondWithDocument(obj, data, w, status, r)
 }
 
 func (res *resource) respondWithPagination(obj Responder, info information, status int, links jsonapi.Links, w http.ResponseWriter, r *http.Request) error {
-	data, err := jsonapi.MarshalToStruct(obj.Result(), info)
+	if streamer, ok := obj.(Streamer); ok {
+		return res.stream(streamer, obj, info, status, links, w, r)
+	}
+
+	data, err := jsonapi.MarshalToStructWithIncludes(obj.Result(), info, getIncludePaths(r))
 	if err != nil {
 		return err
 	}
@@ -1101,7 +1368,7 @@ func (res *resource) respondWithPagination(obj Responder, info information, stat
 		data.Meta = meta
 	}
 
-	return res.marshalResponse(data, w, status, r)
+	return res.respondWithDocument(obj, data, w, status, r)
 }
 
 func unmarshalRequest(r *http.Request) ([]byte, error) {
@@ -1152,26 +1419,32 @@ func filterSparseFields(resp interface{}, r *http.Request) (interface{}, error)
 		}
 
 		if len(wrongFields) > 0 {
-			httpError := NewHTTPError(nil, "Some requested fields were invalid", http.StatusBadRequest)
-			for k, v := range wrongFields {
-				for _, field := range v {
-					httpError.Errors = append(httpError.Errors, Error{
-						Status: "Bad Request",
-						Code:   codeInvalidQueryFields,
-						Title:  fmt.Sprintf(`Field "%s" does not exist for type "%s"`, field, k),
-						Detail: "Please make sure you do only request existing fields",
-						Source: &ErrorSource{
-							Parameter: fmt.Sprintf("fields[%s]", k),
-						},
-					})
-				}
-			}
-			return nil, httpError
+			return nil, invalidFieldsError(wrongFields)
 		}
 	}
 	return resp, nil
 }
 
+// invalidFieldsError returns the error for fields of sparse fieldsets that do not exist
+func invalidFieldsError(wrongFields map[string][]string) HTTPError {
+	httpError := NewHTTPError(nil, "Some requested fields were invalid", http.StatusBadRequest)
+	for k, v := range wrongFields {
+		for _, field := range v {
+			httpError.Errors = append(httpError.Errors, Error{
+				Status: "Bad Request",
+				Code:   codeInvalidQueryFields,
+				Title:  fmt.Sprintf(`Field "%s" does not exist for type "%s"`, field, k),
+				Detail: "Please make sure you do only request existing fields",
+				Source: &ErrorSource{
+					Parameter: fmt.Sprintf("fields[%s]", k),
+				},
+			})
+		}
+	}
+
+	return httpError
+}
+
 func parseQueryFields(query *url.Values) (result map[string][]string) {
 	result = map[string][]string{}
 	for name, param := range *query {
@@ -1221,15 +1494,37 @@ func replaceAttributes(query *map[string][]string, entry *jsonapi.Data) map[stri
 }
 
 func handleError(err error, w http.ResponseWriter, r *http.Request, contentType string) {
-	log.Println(err)
-	if e, ok := err.(HTTPError); ok {
-		writeResult(w, []byte(marshalHTTPError(e)), e.status, contentType)
-		return
+	logError(r, err)
+	e := withDefaultStatus(DefaultErrorHandler(r, err, nil))
+	writeResult(w, []byte(marshalHTTPError(e)), e.status, contentType)
+}
+
+// handleError logs err and writes the HTTPError returned by the ErrorHandler of the api
+func (api *API) handleError(err error, w http.ResponseWriter, r *http.Request, res *resource) {
+	api.logError(r, err)
 
+	errorHandler := api.errorHandler
+	if errorHandler == nil {
+		errorHandler = DefaultErrorHandler
+	}
+
+	var public *Resource
+	if res != nil {
+		public = &Resource{resource: res}
+	}
+
+	e := withDefaultStatus(errorHandler(r, err, public))
+	writeResult(w, []byte(marshalHTTPError(e)), e.status, responseContentType(r, api.ContentType))
+}
+
+// logError logs err with the ErrorLogger of the api
+func (api *API) logError(r *http.Request, err error) {
+	if api.errorLogger != nil {
+		api.errorLogger(r, err)
+		return
 	}
 
-	e := NewHTTPError(err, err.Error(), http.StatusInternalServerError)
-	writeResult(w, []byte(marshalHTTPError(e)), http.StatusInternalServerError, contentType)
+	logError(r, err)
 }
 
 // TODO: this can also be replaced with a struct into that we directly json.Unmarshal
@@ -1239,51 +1534,52 @@ func processRelationshipsData(data interface{}, linkName string, target interfac
 		hasOneID, okID := hasOne["id"].(string)
 		hasOneLID, okLID := hasOne["lid"].(string)
 		if !okID && !okLID {
-			return fmt.Errorf("data object must have a field id or lid for %s", linkName)
+			return relationshipDataError("/data", linkName, fmt.Errorf("data object must have a field id or lid for %s", linkName))
 		}
 
 		target, ok := target.(jsonapi.UnmarshalToOneRelations)
 		if !ok {
-			return errors.New("target struct must implement interface UnmarshalToOneRelations")
+			return relationshipDataError("/data", linkName, errors.New("target struct must implement interface UnmarshalToOneRelations"))
 		}
 
 		err := target.SetToOneReferenceID(linkName, &jsonapi.Identifier{ID: hasOneID, LID: hasOneLID})
 		if err != nil {
-			return err
+			return relationshipDataError("/data", linkName, err)
 		}
 	} else if data == nil {
 		// this means that a to-one relationship must be deleted
 		target, ok := target.(jsonapi.UnmarshalToOneRelations)
 		if !ok {
-			return errors.New("target struct must implement interface UnmarshalToOneRelations")
+			return relationshipDataError("/data", linkName, errors.New("target struct must implement interface UnmarshalToOneRelations"))
 		}
 
 		err := target.SetToOneReferenceID(linkName, nil)
 		if err != nil {
-			return err
+			return relationshipDataError("/data", linkName, err)
 		}
 	} else {
 		hasMany, ok := data.([]interface{})
 		if !ok {
-			return fmt.Errorf("invalid data object or array, must be an object with \"id\" and \"type\" field for %s", linkName)
+			return relationshipDataError("/data", linkName, fmt.Errorf("invalid data object or array, must be an object with \"id\" and \"type\" field for %s", linkName))
 		}
 
 		target, ok := target.(jsonapi.UnmarshalToManyRelations)
 		if !ok {
-			return errors.New("target struct must implement interface UnmarshalToManyRelations")
+			return relationshipDataError("/data", linkName, errors.New("target struct must implement interface UnmarshalToManyRelations"))
 		}
 
 		hasManyRelations := make([]jsonapi.Identifier, 0, len(hasMany))
 
-		for _, entry := range hasMany {
+		for i, entry := range hasMany {
+			pointer := fmt.Sprintf("/data/%d", i)
 			data, ok := entry.(map[string]interface{})
 			if !ok {
-				return fmt.Errorf("entry in data array must be an object for %s", linkName)
+				return relationshipDataError(pointer, linkName, fmt.Errorf("entry in data array must be an object for %s", linkName))
 			}
 			dataID, okID := data["id"].(string)
 			dataLID, okLID := data["lid"].(string)
 			if !okID && !okLID {
-				return fmt.Errorf("all data objects must have a field id or lid for %s", linkName)
+				return relationshipDataError(pointer, linkName, fmt.Errorf("all data objects must have a field id or lid for %s", linkName))
 			}
 
 			hasManyRelations = append(hasManyRelations, jsonapi.Identifier{ID: dataID, LID: dataLID})
@@ -1291,7 +1587,7 @@ func processRelationshipsData(data interface{}, linkName string, target interfac
 
 		err := target.SetToManyReferenceIDs(linkName, hasManyRelations)
 		if err != nil {
-			return err
+			return relationshipDataError("/data", linkName, err)
 		}
 	}
 
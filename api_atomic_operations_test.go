@@ -0,0 +1,236 @@
+package api2go
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/manyminds/api2go/jsonapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type transactionalPostSource struct {
+	*fixtureSource
+	calls     []string
+	commitErr error
+}
+
+func (s *transactionalPostSource) Begin(req Request) error {
+	s.calls = append(s.calls, "begin")
+	return nil
+}
+
+func (s *transactionalPostSource) Commit(req Request) error {
+	s.calls = append(s.calls, "commit")
+	return s.commitErr
+}
+
+func (s *transactionalPostSource) Rollback(req Request) error {
+	s.calls = append(s.calls, "rollback")
+	return nil
+}
+
+// Counter has an attribute that can not be represented exactly as float64
+type Counter struct {
+	ID    string `json:"-"`
+	Count int64  `json:"count"`
+}
+
+func (c Counter) GetID() jsonapi.Identifier {
+	return jsonapi.Identifier{ID: c.ID}
+}
+
+func (c *Counter) SetID(ID jsonapi.Identifier) error {
+	c.ID = ID.ID
+	return nil
+}
+
+type counterSource struct {
+	created []Counter
+	err     error
+}
+
+func (s *counterSource) Create(obj interface{}, req Request) (Responder, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	counter := obj.(Counter)
+	counter.ID = "1"
+	s.created = append(s.created, counter)
+	return &Response{Code: http.StatusNoContent}, nil
+}
+
+var _ = Describe("Atomic operations", func() {
+	var (
+		api    *API
+		rec    *httptest.ResponseRecorder
+		source *transactionalPostSource
+		post   func(string) *http.Request
+	)
+
+	BeforeEach(func() {
+		source = &transactionalPostSource{fixtureSource: &fixtureSource{map[string]*Post{
+			"1": {ID: "1", Title: "Hello, World!"},
+			"2": {ID: "2", Title: "I am NR. 2"},
+		}, false}}
+
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		api.AddResource(Post{}, source)
+		api.AddResource(User{}, &userSource{})
+		api.EnableAtomicOperations()
+		rec = httptest.NewRecorder()
+
+		post = func(body string) *http.Request {
+			req, err := http.NewRequest("POST", "/v1/operations", strings.NewReader(body))
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Content-Type", `application/vnd.api+json; ext="https://jsonapi.org/ext/atomic"`)
+			return req
+		}
+	})
+
+	It("executes all operations in order and resolves local ids", func() {
+		api.Handler().ServeHTTP(rec, post(`{
+			"atomic:operations": [
+				{"op": "add", "data": {"type": "posts", "lid": "a", "attributes": {"title": "New"}}},
+				{"op": "update", "data": {"type": "posts", "lid": "a", "attributes": {"title": "Updated"}}},
+				{"op": "update", "ref": {"type": "posts", "lid": "a", "relationship": "author"}, "data": {"type": "users", "id": "1"}},
+				{"op": "add", "ref": {"type": "posts", "lid": "a", "relationship": "comments"}, "data": [{"type": "comments", "id": "7"}]},
+				{"op": "remove", "ref": {"type": "posts", "id": "2"}}
+			]
+		}`))
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal(`application/vnd.api+json; ext="https://jsonapi.org/ext/atomic"`))
+
+		var document struct {
+			Results []map[string]json.RawMessage `json:"atomic:results"`
+		}
+		Expect(json.Unmarshal(rec.Body.Bytes(), &document)).To(Succeed())
+		Expect(document.Results).To(HaveLen(5))
+		Expect(string(document.Results[0]["data"])).To(ContainSubstring(`"id":"3","lid":"a"`))
+		for _, result := range document.Results[1:] {
+			Expect(result).To(BeEmpty())
+		}
+
+		Expect(source.posts).To(HaveKey("3"))
+		Expect(source.posts).ToNot(HaveKey("2"))
+		Expect(source.posts["3"].Title).To(Equal("Updated"))
+		Expect(source.posts["3"].Author.ID).To(Equal("1"))
+		Expect(source.posts["3"].Comments).To(HaveLen(1))
+		Expect(source.calls).To(Equal([]string{"begin", "commit"}))
+	})
+
+	It("answers with no content if no operation returns data", func() {
+		api.Handler().ServeHTTP(rec, post(`{"atomic:operations": [{"op": "remove", "ref": {"type": "posts", "id": "1"}}]}`))
+		Expect(rec.Code).To(Equal(http.StatusNoContent))
+		Expect(source.posts).ToNot(HaveKey("1"))
+	})
+
+	It("rolls back and points to the failing operation", func() {
+		api.Handler().ServeHTTP(rec, post(`{
+			"atomic:operations": [
+				{"op": "remove", "ref": {"type": "posts", "id": "1"}},
+				{"op": "update", "ref": {"type": "posts", "id": "99"}, "data": {"type": "posts", "id": "99", "attributes": {}}}
+			]
+		}`))
+
+		Expect(rec.Code).To(Equal(http.StatusNotFound))
+		Expect(rec.Body.String()).To(MatchJSON(`{"errors":[{"status":"404","title":"post not found","source":{"pointer":"/atomic:operations/1"}}]}`))
+		Expect(source.calls).To(Equal([]string{"begin", "rollback"}))
+	})
+
+	It("rolls back if commit fails", func() {
+		source.commitErr = errors.New("commit failed")
+		api.Handler().ServeHTTP(rec, post(`{"atomic:operations": [{"op": "remove", "ref": {"type": "posts", "id": "1"}}]}`))
+		Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		Expect(source.calls).To(Equal([]string{"begin", "commit", "rollback"}))
+	})
+
+	It("rejects unknown types and operations", func() {
+		api.Handler().ServeHTTP(rec, post(`{"atomic:operations": [{"op": "add", "data": {"type": "bananas"}}]}`))
+		Expect(rec.Code).To(Equal(http.StatusNotFound))
+
+		rec = httptest.NewRecorder()
+		api.Handler().ServeHTTP(rec, post(`{"atomic:operations": [{"op": "upsert", "data": {"type": "posts"}}]}`))
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(MatchJSON(`{"errors":[{"status":"400","title":"Unknown operation \"upsert\"","source":{"pointer":"/atomic:operations/0"}}]}`))
+	})
+
+	It("rejects documents without operations", func() {
+		api.Handler().ServeHTTP(rec, post(`{"data": {"type": "posts"}}`))
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("requires the atomic extension in the Content-Type", func() {
+		req := post(`{"atomic:operations": [{"op": "remove", "ref": {"type": "posts", "id": "1"}}]}`)
+		req.Header.Set("Content-Type", "application/vnd.api+json")
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusUnsupportedMediaType))
+		Expect(source.posts).To(HaveKey("1"))
+	})
+
+	It("rejects local ids that no previous operation assigned", func() {
+		api.Handler().ServeHTTP(rec, post(`{
+			"atomic:operations": [
+				{"op": "remove", "ref": {"type": "posts", "id": "1"}},
+				{"op": "remove", "ref": {"type": "posts", "lid": "unknown"}}
+			]
+		}`))
+
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring(`"source":{"pointer":"/atomic:operations/1/ref/lid"}`))
+		Expect(source.calls).To(Equal([]string{"begin", "rollback"}))
+	})
+
+	It("rejects local ids in relationships that no previous operation assigned", func() {
+		api.Handler().ServeHTTP(rec, post(`{
+			"atomic:operations": [
+				{"op": "add", "data": {"type": "posts", "lid": "a", "attributes": {"title": "New"}, "relationships": {"author": {"data": {"type": "users", "lid": "unknown"}}}}}
+			]
+		}`))
+
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring(`"source":{"pointer":"/atomic:operations/0/data/relationships/author/data/lid"}`))
+		Expect(source.posts).To(HaveLen(2))
+
+		rec = httptest.NewRecorder()
+		api.Handler().ServeHTTP(rec, post(`{
+			"atomic:operations": [
+				{"op": "add", "ref": {"type": "posts", "id": "1", "relationship": "comments"}, "data": [{"type": "comments", "id": "7"}, {"type": "comments", "lid": "unknown"}]}
+			]
+		}`))
+
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring(`"source":{"pointer":"/atomic:operations/0/data/1/lid"}`))
+		Expect(source.posts["1"].Comments).To(BeEmpty())
+	})
+
+	It("keeps the precision of numbers in the data of operations", func() {
+		counters := &counterSource{}
+		api.AddResource(Counter{}, counters)
+
+		api.Handler().ServeHTTP(rec, post(`{"atomic:operations": [{"op": "add", "data": {"type": "counters", "attributes": {"count": 9007199254740993}}}]}`))
+		Expect(rec.Code).To(Equal(http.StatusNoContent))
+		Expect(counters.created).To(Equal([]Counter{{ID: "1", Count: 9007199254740993}}))
+	})
+
+	It("passes other errors than HTTPErrors to the error handler", func() {
+		cause := errors.New("connection to db.internal refused")
+		api.AddResource(Counter{}, &counterSource{err: cause})
+
+		var handled error
+		api.SetErrorHandler(func(r *http.Request, err error, res *Resource) HTTPError {
+			handled = err
+			return NewHTTPError(err, "Internal Server Error", http.StatusInternalServerError)
+		})
+
+		api.Handler().ServeHTTP(rec, post(`{"atomic:operations": [{"op": "add", "data": {"type": "counters", "attributes": {"count": 1}}}]}`))
+		Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		Expect(handled).To(Equal(cause))
+		Expect(rec.Body.String()).ToNot(ContainSubstring("db.internal"))
+	})
+})
@@ -0,0 +1,150 @@
+package api2go
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type hintedResponse struct {
+	Response
+	policy *CachePolicy
+}
+
+func (r hintedResponse) CachePolicy() *CachePolicy {
+	return r.policy
+}
+
+type hintedArticleSource struct {
+	*articleSource
+	policy *CachePolicy
+}
+
+func (s *hintedArticleSource) FindOne(id string, req Request) (Responder, error) {
+	return hintedResponse{Response: Response{Res: s.articles[id]}, policy: s.policy}, nil
+}
+
+type editorSource struct {
+	userSource
+}
+
+func (s *editorSource) FindAll(req Request) (Responder, error) {
+	return &Response{Res: []User{{ID: "7", Name: "Editor"}}}, nil
+}
+
+var _ = Describe("Cache headers", func() {
+	var (
+		api      *API
+		rec      *httptest.ResponseRecorder
+		source   *hintedArticleSource
+		resource *Resource
+	)
+
+	BeforeEach(func() {
+		source = &hintedArticleSource{articleSource: &articleSource{articles: map[string]Article{
+			"1": {ID: "1", Title: "Hello", Revision: 3, Modified: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+			"2": {ID: "2", Title: "World", Revision: 1},
+		}}}
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		resource = api.AddResource(Article{}, source)
+		rec = httptest.NewRecorder()
+	})
+
+	request := func(method, url, body string) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+	}
+
+	It("sends no cache headers without a policy", func() {
+		request("GET", "/v1/articles/1", "")
+		Expect(rec.Header().Get("Cache-Control")).To(BeEmpty())
+		Expect(rec.Header().Get("Vary")).To(BeEmpty())
+	})
+
+	It("sends the policy of the resource for singles and collections", func() {
+		resource.SetCachePolicy(CachePolicy{MaxAge: time.Minute, SharedMaxAge: time.Hour, Vary: []string{"authorization"}})
+
+		request("GET", "/v1/articles/1", "")
+		Expect(rec.Header().Get("Cache-Control")).To(Equal("public, max-age=60, s-maxage=3600"))
+		Expect(rec.Header().Values("Vary")).To(Equal([]string{"Accept", "Authorization"}))
+
+		rec = httptest.NewRecorder()
+		request("GET", "/v1/articles", "")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Cache-Control")).To(Equal("public, max-age=60, s-maxage=3600"))
+		Expect(rec.Header().Get("Last-Modified")).To(Equal("Wed, 01 May 2024 12:00:00 GMT"))
+	})
+
+	It("sends private and no-store policies", func() {
+		resource.SetCachePolicy(CachePolicy{MaxAge: 30 * time.Second, SharedMaxAge: time.Hour, Private: true, MustRevalidate: true})
+		request("GET", "/v1/articles/1", "")
+		Expect(rec.Header().Get("Cache-Control")).To(Equal("private, max-age=30, must-revalidate"))
+
+		resource.SetCachePolicy(CachePolicy{NoStore: true, MaxAge: time.Hour})
+		rec = httptest.NewRecorder()
+		request("GET", "/v1/articles/1", "")
+		Expect(rec.Header().Get("Cache-Control")).To(Equal("no-store"))
+	})
+
+	It("lets responses override the policy with CacheHints", func() {
+		resource.SetCachePolicy(CachePolicy{MaxAge: time.Minute})
+		source.policy = &CachePolicy{MaxAge: time.Hour}
+
+		request("GET", "/v1/articles/1", "")
+		Expect(rec.Header().Get("Cache-Control")).To(Equal("public, max-age=3600"))
+	})
+
+	It("uses CacheHints without a resource policy", func() {
+		source.policy = &CachePolicy{NoStore: true}
+		request("GET", "/v1/articles/1", "")
+		Expect(rec.Header().Get("Cache-Control")).To(Equal("no-store"))
+	})
+
+	It("sends the cache headers with 304 responses", func() {
+		resource.SetCachePolicy(CachePolicy{MaxAge: time.Minute})
+
+		req, err := http.NewRequest("GET", "/v1/articles/1", nil)
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("If-None-Match", `"3"`)
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusNotModified))
+		Expect(rec.Header().Get("Cache-Control")).To(Equal("public, max-age=60"))
+	})
+
+	It("does not send cache headers with errors of the response", func() {
+		resource.SetCachePolicy(CachePolicy{MaxAge: time.Hour})
+
+		request("GET", "/v1/articles/1?fields[articles]=nope", "")
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Header().Get("Cache-Control")).To(BeEmpty())
+		Expect(rec.Header().Get("Vary")).To(BeEmpty())
+		Expect(rec.Header().Get("ETag")).To(BeEmpty())
+
+		rec = httptest.NewRecorder()
+		request("GET", "/v1/articles?fields[articles]=nope", "")
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Header().Get("Cache-Control")).To(BeEmpty())
+		Expect(rec.Header().Get("Last-Modified")).To(BeEmpty())
+	})
+
+	It("sends the policy of the related resource for related collections", func() {
+		resource.SetCachePolicy(CachePolicy{MaxAge: time.Hour})
+		api.AddResource(User{}, &editorSource{}).SetCachePolicy(CachePolicy{MaxAge: time.Minute, Private: true})
+
+		request("GET", "/v1/articles/1/editor", "")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Cache-Control")).To(Equal("private, max-age=60"))
+	})
+
+	It("does not send cache headers for writes", func() {
+		resource.SetCachePolicy(CachePolicy{MaxAge: time.Minute})
+		request("PATCH", "/v1/articles/1", `{"data": {"type": "articles", "id": "1", "attributes": {"title": "Changed"}}}`)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Cache-Control")).To(BeEmpty())
+	})
+})
@@ -0,0 +1,209 @@
+package api2go
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// markingEncoder is a fake content coding that appends a marker to the body
+type markingEncoder struct{}
+
+func (e markingEncoder) Encoding() string {
+	return "mark"
+}
+
+func (e markingEncoder) NewWriter(w io.Writer) io.WriteCloser {
+	return &markingWriter{w: w}
+}
+
+type markingWriter struct {
+	w io.Writer
+}
+
+func (m *markingWriter) Write(data []byte) (int, error) {
+	return m.w.Write(data)
+}
+
+func (m *markingWriter) Close() error {
+	_, err := m.w.Write([]byte("<marked>"))
+	return err
+}
+
+var _ = Describe("Compression", func() {
+	var (
+		api    *API
+		rec    *httptest.ResponseRecorder
+		source *fixtureSource
+	)
+
+	BeforeEach(func() {
+		source = &fixtureSource{map[string]*Post{
+			"1": {ID: "1", Title: strings.Repeat("Hello, World! ", 100)},
+			"2": {ID: "2", Title: "Short"},
+		}, false}
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		api.AddResource(Post{}, source)
+		rec = httptest.NewRecorder()
+	})
+
+	request := func(method, url, acceptEncoding string) {
+		req, err := http.NewRequest(method, url, nil)
+		Expect(err).ToNot(HaveOccurred())
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		api.Handler().ServeHTTP(rec, req)
+	}
+
+	gunzip := func() string {
+		reader, err := gzip.NewReader(rec.Body)
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(reader)
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	It("does not compress without EnableCompression", func() {
+		request("GET", "/v1/posts/1", "gzip")
+		Expect(rec.Header().Get("Content-Encoding")).To(BeEmpty())
+		Expect(rec.Header().Get("Vary")).To(BeEmpty())
+	})
+
+	Context("with compression enabled", func() {
+		BeforeEach(func() {
+			api.EnableCompression(512)
+		})
+
+		It("compresses large responses with gzip", func() {
+			request("GET", "/v1/posts/1", "deflate, gzip")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("Content-Encoding")).To(Equal("gzip"))
+			Expect(rec.Header().Get("Content-Type")).To(Equal(defaultContentTypHeader))
+			Expect(rec.Header().Values("Vary")).To(ContainElement("Accept-Encoding"))
+			Expect(gunzip()).To(ContainSubstring(`"title":"Hello, World! Hello, World!`))
+		})
+
+		It("sends small responses uncompressed", func() {
+			request("GET", "/v1/posts/2", "gzip")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("Content-Encoding")).To(BeEmpty())
+			Expect(rec.Header().Values("Vary")).To(ContainElement("Accept-Encoding"))
+			Expect(rec.Body.String()).To(ContainSubstring(`"title":"Short"`))
+		})
+
+		It("does not compress for clients that do not accept an encoding", func() {
+			request("GET", "/v1/posts/1", "")
+			Expect(rec.Header().Get("Content-Encoding")).To(BeEmpty())
+
+			rec = httptest.NewRecorder()
+			request("GET", "/v1/posts/1", "gzip;q=0, identity")
+			Expect(rec.Header().Get("Content-Encoding")).To(BeEmpty())
+			Expect(rec.Body.String()).To(ContainSubstring("Hello, World!"))
+		})
+
+		It("accepts wildcards", func() {
+			request("GET", "/v1/posts/1", "*")
+			Expect(rec.Header().Get("Content-Encoding")).To(Equal("gzip"))
+		})
+
+		It("compresses error responses", func() {
+			api.Use(func(next HandlerFunc) HandlerFunc {
+				return func(c APIContexter, w http.ResponseWriter, r *http.Request) {
+					handleError(NewForbiddenError(nil, strings.Repeat("forbidden ", 100)), w, r, defaultContentTypHeader)
+				}
+			})
+
+			request("GET", "/v1/posts/1", "gzip")
+			Expect(rec.Code).To(Equal(http.StatusForbidden))
+			Expect(rec.Header().Get("Content-Encoding")).To(Equal("gzip"))
+			Expect(gunzip()).To(ContainSubstring(`"status":"403"`))
+		})
+
+		It("does not compress responses without a body", func() {
+			request("DELETE", "/v1/posts/1", "gzip")
+			Expect(rec.Code).To(Equal(http.StatusNoContent))
+			Expect(rec.Header().Get("Content-Encoding")).To(BeEmpty())
+			Expect(rec.Body.Len()).To(Equal(0))
+		})
+	})
+
+	Context("with versioned resources", func() {
+		var articles *articleSource
+
+		BeforeEach(func() {
+			articles = &articleSource{articles: map[string]Article{"1": {ID: "1", Title: strings.Repeat("Hello ", 200), Revision: 3}}}
+			api.AddResource(Article{}, articles)
+			api.EnableCompression(512)
+		})
+
+		conditionalRequest := func(method, url, body string, header map[string]string) {
+			req, err := http.NewRequest(method, url, strings.NewReader(body))
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Accept-Encoding", "gzip")
+			for key, value := range header {
+				req.Header.Set(key, value)
+			}
+			api.Handler().ServeHTTP(rec, req)
+		}
+
+		It("appends the content coding to strong entity tags of compressed responses", func() {
+			conditionalRequest("GET", "/v1/articles/1", "", nil)
+			Expect(rec.Header().Get("Content-Encoding")).To(Equal("gzip"))
+			Expect(rec.Header().Get("ETag")).To(Equal(`"3-gzip"`))
+		})
+
+		It("keeps the entity tag of uncompressed responses", func() {
+			articles.articles["1"] = Article{ID: "1", Title: "Short", Revision: 3}
+			conditionalRequest("GET", "/v1/articles/1", "", nil)
+			Expect(rec.Header().Get("Content-Encoding")).To(BeEmpty())
+			Expect(rec.Header().Get("ETag")).To(Equal(`"3"`))
+		})
+
+		It("repeats the entity tag of the compressed response with 304", func() {
+			conditionalRequest("GET", "/v1/articles/1", "", map[string]string{"If-None-Match": `"3-gzip"`})
+			Expect(rec.Code).To(Equal(http.StatusNotModified))
+			Expect(rec.Header().Get("Content-Encoding")).To(BeEmpty())
+			Expect(rec.Header().Get("ETag")).To(Equal(`"3-gzip"`))
+		})
+
+		It("matches the entity tags of compressed responses in preconditions", func() {
+			conditionalRequest("GET", "/v1/articles/1", "", map[string]string{"If-None-Match": `"3-gzip"`})
+			Expect(rec.Code).To(Equal(http.StatusNotModified))
+
+			rec = httptest.NewRecorder()
+			conditionalRequest("PATCH", "/v1/articles/1", `{"data": {"type": "articles", "id": "1", "attributes": {"title": "Changed"}}}`,
+				map[string]string{"If-Match": `"3-gzip"`})
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(articles.articles["1"].Title).To(Equal("Changed"))
+
+			rec = httptest.NewRecorder()
+			conditionalRequest("DELETE", "/v1/articles/1", "", map[string]string{"If-Match": `"3-gzip"`})
+			Expect(rec.Code).To(Equal(http.StatusPreconditionFailed))
+		})
+	})
+
+	Context("with custom encoders", func() {
+		BeforeEach(func() {
+			api.EnableCompression(0, markingEncoder{}, GzipEncoder{Level: gzip.BestSpeed})
+		})
+
+		It("prefers the first encoder on equal quality", func() {
+			request("GET", "/v1/posts/2", "gzip, mark")
+			Expect(rec.Header().Get("Content-Encoding")).To(Equal("mark"))
+			Expect(bytes.HasSuffix(rec.Body.Bytes(), []byte("<marked>"))).To(BeTrue())
+		})
+
+		It("uses the encoding with the highest quality", func() {
+			request("GET", "/v1/posts/2", "gzip, mark;q=0.5")
+			Expect(rec.Header().Get("Content-Encoding")).To(Equal("gzip"))
+			Expect(gunzip()).To(ContainSubstring(`"title":"Short"`))
+		})
+	})
+})
@@ -0,0 +1,270 @@
+package api2go
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manyminds/api2go/jsonapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type Article struct {
+	ID       string    `json:"-"`
+	Title    string    `json:"title"`
+	Revision int       `json:"-"`
+	Modified time.Time `json:"-"`
+	EditorID string    `json:"-"`
+}
+
+func (a Article) GetID() jsonapi.Identifier {
+	return jsonapi.Identifier{ID: a.ID}
+}
+
+func (a *Article) SetID(ID jsonapi.Identifier) error {
+	a.ID = ID.ID
+	return nil
+}
+
+func (a Article) GetReferences() []jsonapi.Reference {
+	return []jsonapi.Reference{{Name: "editor", Type: "users", Relationship: jsonapi.ToOneRelationship}}
+}
+
+func (a Article) GetReferencedIDs() []jsonapi.ReferenceID {
+	if a.EditorID == "" {
+		return []jsonapi.ReferenceID{}
+	}
+	return []jsonapi.ReferenceID{{ID: a.EditorID, Name: "editor", Type: "users", Relationship: jsonapi.ToOneRelationship}}
+}
+
+func (a *Article) SetToOneReferenceID(name string, ID *jsonapi.Identifier) error {
+	a.EditorID = ""
+	if ID != nil {
+		a.EditorID = ID.ID
+	}
+	return nil
+}
+
+func (a Article) Version() Version {
+	return Version{ETag: strconv.Itoa(a.Revision), LastModified: a.Modified}
+}
+
+type articleSource struct {
+	articles map[string]Article
+	reads    int
+}
+
+func (s *articleSource) FindAll(req Request) (Responder, error) {
+	return &Response{Res: []Article{s.articles["1"]}}, nil
+}
+
+func (s *articleSource) FindOne(id string, req Request) (Responder, error) {
+	s.reads++
+	article, ok := s.articles[id]
+	if !ok {
+		return nil, NewNotFoundError(nil, "article not found")
+	}
+	return &Response{Res: article}, nil
+}
+
+func (s *articleSource) Create(obj interface{}, req Request) (Responder, error) {
+	return &Response{Res: obj, Code: http.StatusCreated}, nil
+}
+
+func (s *articleSource) Delete(id string, req Request) (Responder, error) {
+	delete(s.articles, id)
+	return &Response{Code: http.StatusNoContent}, nil
+}
+
+func (s *articleSource) Update(obj interface{}, req Request) (Responder, error) {
+	article := obj.(Article)
+	article.Revision++
+	s.articles[article.ID] = article
+	return &Response{Res: article, Code: http.StatusOK}, nil
+}
+
+// articleDeleter can only delete articles, so it can not check preconditions
+type articleDeleter struct {
+	deleted []string
+}
+
+func (s *articleDeleter) Delete(id string, req Request) (Responder, error) {
+	s.deleted = append(s.deleted, id)
+	return &Response{Code: http.StatusNoContent}, nil
+}
+
+var _ = Describe("Conditional requests", func() {
+	var (
+		api      *API
+		rec      *httptest.ResponseRecorder
+		source   *articleSource
+		modified time.Time
+	)
+
+	BeforeEach(func() {
+		modified = time.Date(2024, 5, 1, 12, 30, 15, 500, time.UTC)
+		source = &articleSource{articles: map[string]Article{"1": {ID: "1", Title: "Hello", Revision: 3, Modified: modified}}}
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		api.AddResource(Article{}, source)
+		rec = httptest.NewRecorder()
+	})
+
+	request := func(method, url, body string, header map[string]string) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+		for key, value := range header {
+			req.Header.Set(key, value)
+		}
+		api.Handler().ServeHTTP(rec, req)
+	}
+
+	Context("reading", func() {
+		It("sends the version headers", func() {
+			request("GET", "/v1/articles/1", "", nil)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("ETag")).To(Equal(`"3"`))
+			Expect(rec.Header().Get("Last-Modified")).To(Equal("Wed, 01 May 2024 12:30:15 GMT"))
+		})
+
+		It("returns 304 for a matching If-None-Match", func() {
+			request("GET", "/v1/articles/1", "", map[string]string{"If-None-Match": `"2", W/"3"`})
+			Expect(rec.Code).To(Equal(http.StatusNotModified))
+			Expect(rec.Body.Len()).To(Equal(0))
+			Expect(rec.Header().Get("ETag")).To(Equal(`"3"`))
+		})
+
+		It("returns the resource for an outdated If-None-Match", func() {
+			request("GET", "/v1/articles/1", "", map[string]string{"If-None-Match": `"2"`})
+			Expect(rec.Code).To(Equal(http.StatusOK))
+		})
+
+		It("returns 304 if the resource was not modified since", func() {
+			request("GET", "/v1/articles/1", "", map[string]string{"If-Modified-Since": "Wed, 01 May 2024 12:30:15 GMT"})
+			Expect(rec.Code).To(Equal(http.StatusNotModified))
+		})
+
+		It("returns the resource if it was modified since", func() {
+			request("GET", "/v1/articles/1", "", map[string]string{"If-Modified-Since": "Wed, 01 May 2024 12:30:14 GMT"})
+			Expect(rec.Code).To(Equal(http.StatusOK))
+		})
+
+		It("prefers If-None-Match over If-Modified-Since", func() {
+			request("GET", "/v1/articles/1", "", map[string]string{
+				"If-None-Match":     `"2"`,
+				"If-Modified-Since": "Wed, 01 May 2024 12:30:15 GMT",
+			})
+			Expect(rec.Code).To(Equal(http.StatusOK))
+		})
+
+		It("sends no version headers for collections", func() {
+			request("GET", "/v1/articles", "", nil)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("ETag")).To(BeEmpty())
+		})
+	})
+
+	Context("updating", func() {
+		body := `{"data": {"type": "articles", "id": "1", "attributes": {"title": "Changed"}}}`
+
+		It("updates with a matching If-Match and sends the new version", func() {
+			request("PATCH", "/v1/articles/1", body, map[string]string{"If-Match": `"3"`})
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("ETag")).To(Equal(`"4"`))
+			Expect(source.articles["1"].Title).To(Equal("Changed"))
+			Expect(source.reads).To(Equal(1))
+		})
+
+		It("rejects an outdated If-Match with 412", func() {
+			request("PATCH", "/v1/articles/1", body, map[string]string{"If-Match": `"2"`})
+			Expect(rec.Code).To(Equal(http.StatusPreconditionFailed))
+			Expect(rec.Body.String()).To(MatchJSON(`{"errors":[{"status":"412","title":"The resource has been modified"}]}`))
+			Expect(source.articles["1"].Title).To(Equal("Hello"))
+		})
+
+		It("rejects weak entity tags in If-Match", func() {
+			request("PATCH", "/v1/articles/1", body, map[string]string{"If-Match": `W/"3"`})
+			Expect(rec.Code).To(Equal(http.StatusPreconditionFailed))
+		})
+
+		It("accepts * as If-Match", func() {
+			request("PATCH", "/v1/articles/1", body, map[string]string{"If-Match": `*`})
+			Expect(rec.Code).To(Equal(http.StatusOK))
+		})
+
+		It("rejects updates of resources modified after If-Unmodified-Since", func() {
+			request("PATCH", "/v1/articles/1", body, map[string]string{"If-Unmodified-Since": "Wed, 01 May 2024 12:00:00 GMT"})
+			Expect(rec.Code).To(Equal(http.StatusPreconditionFailed))
+		})
+
+		It("updates without preconditions", func() {
+			request("PATCH", "/v1/articles/1", body, nil)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("deleting", func() {
+		It("deletes with a matching If-Match", func() {
+			request("DELETE", "/v1/articles/1", "", map[string]string{"If-Match": `"3"`})
+			Expect(rec.Code).To(Equal(http.StatusNoContent))
+			Expect(source.articles).ToNot(HaveKey("1"))
+			Expect(source.reads).To(Equal(1))
+		})
+
+		It("rejects an outdated If-Match with 412", func() {
+			request("DELETE", "/v1/articles/1", "", map[string]string{"If-Match": `"1"`})
+			Expect(rec.Code).To(Equal(http.StatusPreconditionFailed))
+			Expect(source.articles).To(HaveKey("1"))
+		})
+
+		It("returns the error of FindOne for missing resources", func() {
+			request("DELETE", "/v1/articles/2", "", map[string]string{"If-Match": `"1"`})
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("does not load the resource without preconditions", func() {
+			request("DELETE", "/v1/articles/1", "", nil)
+			Expect(rec.Code).To(Equal(http.StatusNoContent))
+			Expect(source.reads).To(Equal(0))
+		})
+
+		It("rejects preconditions with 412 if the resource can not be loaded", func() {
+			deleter := &articleDeleter{}
+			api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+			api.AddResource(Article{}, deleter)
+
+			request("DELETE", "/v1/articles/1", "", map[string]string{"If-Match": `"3"`})
+			Expect(rec.Code).To(Equal(http.StatusPreconditionFailed))
+			Expect(deleter.deleted).To(BeEmpty())
+
+			rec = httptest.NewRecorder()
+			request("DELETE", "/v1/articles/1", "", nil)
+			Expect(rec.Code).To(Equal(http.StatusNoContent))
+			Expect(deleter.deleted).To(Equal([]string{"1"}))
+		})
+	})
+
+	Context("writing relationships", func() {
+		body := `{"data": {"type": "users", "id": "7"}}`
+
+		It("updates with a matching If-Match", func() {
+			request("PATCH", "/v1/articles/1/relationships/editor", body, map[string]string{"If-Match": `"3"`})
+			Expect(rec.Code).To(Equal(http.StatusNoContent))
+			Expect(source.articles["1"].EditorID).To(Equal("7"))
+			Expect(source.reads).To(Equal(1))
+		})
+
+		It("rejects an outdated If-Match with 412", func() {
+			request("PATCH", "/v1/articles/1/relationships/editor", body, map[string]string{"If-Match": `"2"`})
+			Expect(rec.Code).To(Equal(http.StatusPreconditionFailed))
+			Expect(source.articles["1"].EditorID).To(BeEmpty())
+		})
+
+		It("rejects relationship updates of resources modified after If-Unmodified-Since", func() {
+			request("PATCH", "/v1/articles/1/relationships/editor", body, map[string]string{"If-Unmodified-Since": "Wed, 01 May 2024 12:00:00 GMT"})
+			Expect(rec.Code).To(Equal(http.StatusPreconditionFailed))
+		})
+	})
+})
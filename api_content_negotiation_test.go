@@ -0,0 +1,121 @@
+package api2go
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Content negotiation", func() {
+	const (
+		extension = "https://example.com/ext/version"
+		profile   = "https://example.com/profiles/timestamps"
+	)
+
+	var (
+		api    *API
+		rec    *httptest.ResponseRecorder
+		source *sortableCommentSource
+	)
+
+	BeforeEach(func() {
+		source = &sortableCommentSource{}
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		api.AddResource(Comment{}, source)
+		api.RegisterExtension(extension)
+		api.RegisterProfile(profile)
+		rec = httptest.NewRecorder()
+	})
+
+	request := func(header http.Header) {
+		req, err := http.NewRequest("GET", "/v1/comments", nil)
+		Expect(err).ToNot(HaveOccurred())
+		req.Header = header
+		api.Handler().ServeHTTP(rec, req)
+	}
+
+	It("accepts requests without Content-Type and Accept headers", func() {
+		request(http.Header{})
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal(defaultContentTypHeader))
+		Expect(source.lastRequest.Extensions).To(BeNil())
+		Expect(source.lastRequest.Profiles).To(BeNil())
+	})
+
+	It("rejects a Content-Type with unknown media type parameters", func() {
+		request(http.Header{"Content-Type": {defaultContentTypHeader + "; charset=utf-8"}})
+		Expect(rec.Code).To(Equal(http.StatusUnsupportedMediaType))
+		Expect(rec.Body.String()).To(ContainSubstring(`"status":"415"`))
+	})
+
+	It("rejects a Content-Type with unregistered extensions", func() {
+		request(http.Header{"Content-Type": {defaultContentTypHeader + `; ext="https://example.com/ext/unknown"`}})
+		Expect(rec.Code).To(Equal(http.StatusUnsupportedMediaType))
+	})
+
+	It("ignores other media types in the Content-Type", func() {
+		request(http.Header{"Content-Type": {"application/json; charset=utf-8"}})
+		Expect(rec.Code).To(Equal(http.StatusOK))
+	})
+
+	It("applies registered extensions and profiles", func() {
+		request(http.Header{"Content-Type": {defaultContentTypHeader + `; ext="` + extension + `"; profile="` + profile + ` https://example.com/profiles/unknown"`}})
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(source.lastRequest.Extensions).To(Equal([]string{extension}))
+		Expect(source.lastRequest.Profiles).To(Equal([]string{profile}))
+		Expect(rec.Header().Get("Content-Type")).To(Equal(defaultContentTypHeader + `; ext="` + extension + `"; profile="` + profile + `"`))
+	})
+
+	It("rejects an Accept header where every JSON:API media type has unknown parameters", func() {
+		request(http.Header{"Accept": {defaultContentTypHeader + "; charset=utf-8, " + defaultContentTypHeader + `; ext="https://example.com/ext/unknown"`}})
+		Expect(rec.Code).To(Equal(http.StatusNotAcceptable))
+		Expect(rec.Body.String()).To(ContainSubstring(`"status":"406"`))
+	})
+
+	It("answers with the JSON:API media type if the Accept header has none", func() {
+		request(http.Header{"Accept": {"application/json"}})
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal(defaultContentTypHeader))
+
+		rec = httptest.NewRecorder()
+		request(http.Header{"Accept": {"text/html"}})
+		Expect(rec.Code).To(Equal(http.StatusOK))
+	})
+
+	It("rejects an Accept header where the JSON:API media type has a quality of zero", func() {
+		request(http.Header{"Accept": {defaultContentTypHeader + "; q=0"}})
+		Expect(rec.Code).To(Equal(http.StatusNotAcceptable))
+
+		rec = httptest.NewRecorder()
+		request(http.Header{"Accept": {"application/json, " + defaultContentTypHeader + "; q=0.0"}})
+		Expect(rec.Code).To(Equal(http.StatusNotAcceptable))
+	})
+
+	It("accepts an Accept header with at least one supported JSON:API media type", func() {
+		request(http.Header{"Accept": {defaultContentTypHeader + "; charset=utf-8, " + defaultContentTypHeader + "; q=0.5"}})
+		Expect(rec.Code).To(Equal(http.StatusOK))
+	})
+
+	It("accepts wildcard media types", func() {
+		request(http.Header{"Accept": {"text/html, */*"}})
+		Expect(rec.Code).To(Equal(http.StatusOK))
+	})
+
+	It("applies profiles requested in the Accept header", func() {
+		request(http.Header{"Accept": {defaultContentTypHeader + `; profile="` + profile + `"`}})
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(source.lastRequest.Profiles).To(Equal([]string{profile}))
+		Expect(strings.HasSuffix(rec.Header().Get("Content-Type"), `; profile="`+profile+`"`)).To(BeTrue())
+	})
+
+	It("does not negotiate OPTIONS requests", func() {
+		req, err := http.NewRequest("OPTIONS", "/v1/comments", nil)
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Accept", "text/html")
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusNoContent))
+	})
+})
@@ -0,0 +1,205 @@
+package api2go
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// cursorCommentSource pages through five comments, the cursor of a comment is "c" + id
+type cursorCommentSource struct {
+	lastRequest *Request
+}
+
+func (s *cursorCommentSource) CursorPaginatedFindAll(req Request) (Cursors, Responder, error) {
+	s.lastRequest = &req
+
+	size := 2
+	if value, ok := req.Pagination["size"]; ok {
+		size, _ = strconv.Atoi(value)
+	}
+
+	start := 1
+	if after, ok := req.Pagination["after"]; ok {
+		id, _ := strconv.Atoi(after[1:])
+		start = id + 1
+	} else if before, ok := req.Pagination["before"]; ok {
+		id, _ := strconv.Atoi(before[1:])
+		start = id - size
+	}
+
+	comments := []Comment{}
+	for id := start; id < start+size && id <= 5; id++ {
+		comments = append(comments, Comment{ID: strconv.Itoa(id)})
+	}
+
+	var cursors Cursors
+	if start+size <= 5 {
+		cursors.Next = "c" + comments[len(comments)-1].ID
+	}
+	if start > 1 {
+		cursors.Prev = "c" + comments[0].ID
+	}
+
+	return cursors, &Response{Res: comments}, nil
+}
+
+var _ = Describe("Cursor based pagination", func() {
+	var (
+		api    *API
+		rec    *httptest.ResponseRecorder
+		source *cursorCommentSource
+		links  func() map[string]string
+		ids    func() []string
+	)
+
+	BeforeEach(func() {
+		source = &cursorCommentSource{}
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		api.AddResource(Post{}, &fixtureSource{map[string]*Post{"1": {ID: "1"}}, false})
+		api.AddResource(Comment{}, source)
+		rec = httptest.NewRecorder()
+
+		var document struct {
+			Links map[string]string `json:"links"`
+			Data  []struct {
+				ID string `json:"id"`
+			} `json:"data"`
+		}
+		links = func() map[string]string {
+			Expect(json.Unmarshal(rec.Body.Bytes(), &document)).To(Succeed())
+			return document.Links
+		}
+		ids = func() []string {
+			Expect(json.Unmarshal(rec.Body.Bytes(), &document)).To(Succeed())
+			result := []string{}
+			for _, data := range document.Data {
+				result = append(result, data.ID)
+			}
+			return result
+		}
+	})
+
+	It("is used without pagination parameters if FindAll is not implemented", func() {
+		req, err := http.NewRequest("GET", "/v1/comments", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(ids()).To(Equal([]string{"1", "2"}))
+		Expect(links()).To(Equal(map[string]string{"next": "/v1/comments?page[after]=c2"}))
+	})
+
+	It("generates next and prev links", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?page[after]=c1&page[size]=2", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(source.lastRequest.Pagination).To(Equal(map[string]string{"after": "c1", "size": "2"}))
+		Expect(ids()).To(Equal([]string{"2", "3"}))
+		Expect(links()).To(Equal(map[string]string{
+			"next": "/v1/comments?page[after]=c3&page[size]=2",
+			"prev": "/v1/comments?page[before]=c2&page[size]=2",
+		}))
+	})
+
+	It("pages backwards with page[before]", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?page[before]=c5&page[size]=2", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(ids()).To(Equal([]string{"3", "4"}))
+		Expect(links()).To(Equal(map[string]string{
+			"next": "/v1/comments?page[after]=c4&page[size]=2",
+			"prev": "/v1/comments?page[before]=c3&page[size]=2",
+		}))
+	})
+
+	It("omits the next link on the last page", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?page[after]=c3&page[size]=2", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(ids()).To(Equal([]string{"4", "5"}))
+		Expect(links()).To(Equal(map[string]string{"prev": "/v1/comments?page[before]=c4&page[size]=2"}))
+	})
+
+	It("works for related collections", func() {
+		req, err := http.NewRequest("GET", "/v1/posts/1/comments?page[size]=3", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(source.lastRequest.QueryParams["postsID"]).To(Equal([]string{"1"}))
+		Expect(ids()).To(Equal([]string{"1", "2", "3"}))
+		Expect(links()).To(Equal(map[string]string{"next": "/v1/posts/1/comments?page[after]=c3&page[size]=3"}))
+	})
+
+	It("keeps opaque cursors escaped", func() {
+		params := url.Values{}
+		params.Set("page[after]", "a+b=&c")
+		Expect(encodePaginationQuery(params)).To(Equal("page[after]=a%2Bb%3D%26c"))
+	})
+
+	It("keeps brackets in values and other parameters escaped", func() {
+		params := url.Values{}
+		params.Set("page[after]", "[a]%5B")
+		params.Set("filter[value]", "x]")
+		Expect(encodePaginationQuery(params)).To(Equal("filter%5Bvalue%5D=x%5D&page[after]=%5Ba%5D%255B"))
+	})
+
+	It("rejects cursor parameters mixed with number/size pagination", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?page[number]=1&page[size]=2&page[after]=c1", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(source.lastRequest).To(BeNil())
+
+		var document HTTPError
+		Expect(json.Unmarshal(rec.Body.Bytes(), &document)).To(Succeed())
+		Expect(document.Errors).To(HaveLen(1))
+		Expect(document.Errors[0].Code).To(Equal(codeInvalidQueryPagination))
+		Expect(document.Errors[0].Source.Parameter).To(Equal("page[after]"))
+	})
+
+	It("rejects cursor parameters mixed with offset/limit pagination of related collections", func() {
+		req, err := http.NewRequest("GET", "/v1/posts/1/comments?page[offset]=1&page[before]=c3", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring(`"parameter":"page[before]"`))
+	})
+
+	It("rejects number/size pagination if only cursor pagination is implemented", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?page[number]=1&page[size]=2", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(source.lastRequest).To(BeNil())
+
+		var document HTTPError
+		Expect(json.Unmarshal(rec.Body.Bytes(), &document)).To(Succeed())
+		Expect(document.Errors).To(HaveLen(1))
+		Expect(document.Errors[0].Code).To(Equal(codeInvalidQueryPagination))
+		Expect(document.Errors[0].Source.Parameter).To(Equal("page[number]"))
+	})
+
+	It("rejects offset/limit pagination of related collections if only cursor pagination is implemented", func() {
+		req, err := http.NewRequest("GET", "/v1/posts/1/comments?page[limit]=2", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring(`"parameter":"page[limit]"`))
+	})
+
+	It("does not treat cursor parameters as number/size or offset/limit pagination", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?page[number]=1&page[size]=2&page[after]=c1", nil)
+		Expect(err).ToNot(HaveOccurred())
+		pagination := newPaginationQueryParams(req)
+		Expect(pagination.isValid()).To(BeFalse())
+		Expect(pagination.isCursor()).To(BeFalse())
+	})
+})
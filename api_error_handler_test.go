@@ -0,0 +1,128 @@
+package api2go
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var errQueryParameter = errors.New("Did not receive query parameter")
+
+var _ = Describe("Error handling", func() {
+	var (
+		api    *API
+		rec    *httptest.ResponseRecorder
+		logged []string
+	)
+
+	BeforeEach(func() {
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		api.AddResource(User{}, &userSource{})
+		rec = httptest.NewRecorder()
+		logged = nil
+	})
+
+	request := func(method, url, body string) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+	}
+
+	It("renders other errors as 500 with their message by default", func() {
+		request("GET", "/v1/users", "")
+		Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		Expect(rec.Body.String()).To(MatchJSON(`{"errors":[{"status":"500","title":"Did not receive query parameter"}]}`))
+	})
+
+	It("maps errors with the error handler", func() {
+		api.SetErrorHandler(func(r *http.Request, err error, res *Resource) HTTPError {
+			Expect(r.URL.Path).To(Equal("/v1/users"))
+			Expect(res.Name()).To(Equal("users"))
+			if err.Error() == errQueryParameter.Error() {
+				return NewHTTPError(err, "Missing postsID", http.StatusBadRequest)
+			}
+			return NewHTTPError(err, "Internal Server Error", http.StatusInternalServerError)
+		})
+
+		request("GET", "/v1/users", "")
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(MatchJSON(`{"errors":[{"status":"400","title":"Missing postsID"}]}`))
+	})
+
+	It("passes HTTPErrors to the error handler", func() {
+		api.SetErrorHandler(func(r *http.Request, err error, res *Resource) HTTPError {
+			httpErr, ok := err.(HTTPError)
+			Expect(ok).To(BeTrue())
+			return httpErr
+		})
+
+		api.AddResource(Ticket{}, &ticketSource{tickets: map[string]Ticket{}})
+
+		request("POST", "/v1/tickets", `{"data": {"type": "posts", "attributes": {"title": "New"}}}`)
+		Expect(rec.Code).To(Equal(http.StatusConflict))
+		Expect(rec.Body.String()).To(MatchJSON(`{"errors":[{"status":"409","title":"Type posts in JSON does not match target struct type tickets","source":{"pointer":"/data/type"}}]}`))
+	})
+
+	It("passes no resource for routes that do not belong to one", func() {
+		var called bool
+		api.EnableAtomicOperations()
+		api.SetErrorHandler(func(r *http.Request, err error, res *Resource) HTTPError {
+			called = true
+			Expect(res).To(BeNil())
+			return DefaultErrorHandler(r, err, res)
+		})
+
+		request("POST", "/v1/operations", `{}`)
+		Expect(called).To(BeTrue())
+		Expect(rec.Code).To(Equal(http.StatusUnsupportedMediaType))
+	})
+
+	It("logs the original error with the error logger", func() {
+		api.SetErrorLogger(func(r *http.Request, err error) {
+			logged = append(logged, r.Method+" "+err.Error())
+		})
+		api.SetErrorHandler(func(r *http.Request, err error, res *Resource) HTTPError {
+			return NewHTTPError(err, "Internal Server Error", http.StatusInternalServerError)
+		})
+
+		request("GET", "/v1/users", "")
+		Expect(logged).To(Equal([]string{"GET Did not receive query parameter"}))
+		Expect(rec.Body.String()).ToNot(ContainSubstring("query parameter"))
+	})
+
+	It("answers HTTPErrors without a status with 500", func() {
+		api.SetErrorHandler(func(r *http.Request, err error, res *Resource) HTTPError {
+			return HTTPError{Errors: []Error{{Title: "Unknown"}}}
+		})
+
+		request("GET", "/v1/users", "")
+		Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		Expect(rec.Body.String()).To(MatchJSON(`{"errors":[{"title":"Unknown"}]}`))
+	})
+
+	It("lets the error handler set the status of not allowed methods", func() {
+		api = NewAPI(testPrefix)
+		api.AddResource(User{}, &userSource{})
+		api.SetErrorHandler(func(r *http.Request, err error, res *Resource) HTTPError {
+			return NewHTTPError(err, "Not Found", http.StatusNotFound)
+		})
+
+		request("PUT", "/v1/users/1", "")
+		Expect(rec.Code).To(Equal(http.StatusNotFound))
+		Expect(rec.Body.String()).To(MatchJSON(`{"errors":[{"status":"404","title":"Not Found"}]}`))
+	})
+
+	It("restores the defaults with nil", func() {
+		api.SetErrorHandler(func(r *http.Request, err error, res *Resource) HTTPError {
+			return NewHTTPError(err, "Internal Server Error", http.StatusInternalServerError)
+		})
+		api.SetErrorHandler(nil)
+
+		request("GET", "/v1/users", "")
+		Expect(rec.Body.String()).To(ContainSubstring("Did not receive query parameter"))
+	})
+})
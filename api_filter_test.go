@@ -0,0 +1,200 @@
+package api2go
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type filterableCommentSource struct {
+	commentSource
+	lastRequest *Request
+}
+
+func (s *filterableCommentSource) FindAll(req Request) (Responder, error) {
+	s.lastRequest = &req
+	return &Response{Res: []Comment{}}, nil
+}
+
+func (s *filterableCommentSource) FilterableFields() map[string][]FilterOperator {
+	return map[string][]FilterOperator{
+		"value":  {FilterEqual, FilterLike},
+		"rating": {FilterGreaterThanOrEqual, FilterLessThan},
+	}
+}
+
+var _ = Describe("Filter query parameters", func() {
+	var (
+		api    *API
+		rec    *httptest.ResponseRecorder
+		source *filterableCommentSource
+	)
+
+	BeforeEach(func() {
+		source = &filterableCommentSource{}
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		api.AddResource(Post{}, &fixtureSource{map[string]*Post{"1": {ID: "1"}}, false})
+		api.AddResource(Comment{}, source)
+		rec = httptest.NewRecorder()
+	})
+
+	It("parses fields, operators and values", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?filter[rating][gte]=10&filter[value]=open,closed&filter[rating][lt]=20&filter=ignored&filter[a][b][c]=ignored", nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parseFilters(req)).To(Equal(FilterGroup{
+			Operator: FilterAnd,
+			Filters: []Filter{
+				{Field: "rating", Operator: FilterGreaterThanOrEqual, Values: []string{"10"}},
+				{Field: "rating", Operator: FilterLessThan, Values: []string{"20"}},
+				{Field: "value", Operator: FilterEqual, Values: []string{"open", "closed"}},
+			},
+		}))
+	})
+
+	It("parses groups into a filter tree", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?filter[value]=a&filter[or][1][rating][lt]=2&filter[or][0][value]=b&filter[or][0][rating][gte]=3&filter[or][1][and][0][value]=c", nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parseFilters(req)).To(Equal(FilterGroup{
+			Operator: FilterAnd,
+			Filters:  []Filter{{Field: "value", Operator: FilterEqual, Values: []string{"a"}}},
+			Groups: []FilterGroup{{
+				Operator: FilterOr,
+				Groups: []FilterGroup{
+					{
+						Operator: FilterAnd,
+						Filters: []Filter{
+							{Field: "rating", Operator: FilterGreaterThanOrEqual, Values: []string{"3"}},
+							{Field: "value", Operator: FilterEqual, Values: []string{"b"}},
+						},
+					},
+					{
+						Operator: FilterAnd,
+						Filters:  []Filter{{Field: "rating", Operator: FilterLessThan, Values: []string{"2"}}},
+						Groups: []FilterGroup{{
+							Operator: FilterAnd,
+							Groups: []FilterGroup{{
+								Operator: FilterAnd,
+								Filters:  []Filter{{Field: "value", Operator: FilterEqual, Values: []string{"c"}}},
+							}},
+						}},
+					},
+				},
+			}},
+		}))
+	})
+
+	It("has no filters without filter parameters", func() {
+		req, err := http.NewRequest("GET", "/v1/comments", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(source.lastRequest.Filters).To(BeNil())
+		Expect(source.lastRequest.FilterGroups).To(BeNil())
+	})
+
+	It("passes the filters to FindAll", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?filter[value][like]=stupid", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(source.lastRequest.Filters).To(Equal([]Filter{
+			{Field: "value", Operator: FilterLike, Values: []string{"stupid"}},
+		}))
+	})
+
+	It("passes the filter groups to FindAll", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?filter[or][0][value]=a&filter[or][1][rating][lt]=3", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(source.lastRequest.Filters).To(BeNil())
+		Expect(source.lastRequest.FilterGroups).To(Equal([]FilterGroup{{
+			Operator: FilterOr,
+			Groups: []FilterGroup{
+				{Operator: FilterAnd, Filters: []Filter{{Field: "value", Operator: FilterEqual, Values: []string{"a"}}}},
+				{Operator: FilterAnd, Filters: []Filter{{Field: "rating", Operator: FilterLessThan, Values: []string{"3"}}}},
+			},
+		}}))
+	})
+
+	It("passes the filters to FindAll of related collections", func() {
+		req, err := http.NewRequest("GET", "/v1/posts/1/comments?filter[rating][gte]=3", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(source.lastRequest.Filters).To(Equal([]Filter{
+			{Field: "rating", Operator: FilterGreaterThanOrEqual, Values: []string{"3"}},
+		}))
+	})
+
+	It("rejects unknown fields and operators", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?filter[title]=foo&filter[rating]=3", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(source.lastRequest).To(BeNil())
+
+		var document HTTPError
+		Expect(json.Unmarshal(rec.Body.Bytes(), &document)).To(Succeed())
+		Expect(document.Errors).To(Equal([]Error{
+			{
+				Status: "400",
+				Code:   codeInvalidQueryFilter,
+				Title:  `Operator "eq" is not supported for filter "rating" of type "comments"`,
+				Detail: "Please make sure you do only use supported filter operators",
+				Source: &ErrorSource{Parameter: "filter[rating]"},
+			},
+			{
+				Status: "400",
+				Code:   codeInvalidQueryFilter,
+				Title:  `Filtering by "title" is not supported for type "comments"`,
+				Detail: "Please make sure you do only filter by supported fields",
+				Source: &ErrorSource{Parameter: "filter[title]"},
+			},
+		}))
+	})
+
+	It("rejects malformed filter parameters", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?filter=x&filter[a][b][c]=y&filter[or][x][value]=z", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(source.lastRequest).To(BeNil())
+
+		var document HTTPError
+		Expect(json.Unmarshal(rec.Body.Bytes(), &document)).To(Succeed())
+		var parameters []string
+		for _, e := range document.Errors {
+			Expect(e.Code).To(Equal(codeInvalidQueryFilter))
+			parameters = append(parameters, e.Source.Parameter)
+		}
+		Expect(parameters).To(Equal([]string{"filter", "filter[a][b][c]", "filter[or][x][value]"}))
+	})
+
+	It("validates the filters of groups", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?filter[or][0][value]=a&filter[or][1][title]=b", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+
+		var document HTTPError
+		Expect(json.Unmarshal(rec.Body.Bytes(), &document)).To(Succeed())
+		Expect(document.Errors).To(HaveLen(1))
+		Expect(document.Errors[0].Source.Parameter).To(Equal("filter[or][1][title]"))
+	})
+
+	It("reports the operator in the error source", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?filter[value][gt]=foo", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+
+		var document HTTPError
+		Expect(json.Unmarshal(rec.Body.Bytes(), &document)).To(Succeed())
+		Expect(document.Errors).To(HaveLen(1))
+		Expect(document.Errors[0].Source.Parameter).To(Equal("filter[value][gt]"))
+	})
+})
@@ -0,0 +1,185 @@
+package api2go
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type hookedPostSource struct {
+	*fixtureSource
+	calls []string
+	abort string
+}
+
+func (s *hookedPostSource) record(name string) error {
+	s.calls = append(s.calls, name)
+	if name == s.abort {
+		return NewHTTPError(nil, "Forbidden by "+name, http.StatusForbidden)
+	}
+	return nil
+}
+
+func (s *hookedPostSource) BeforeCreate(obj interface{}, req Request) error {
+	post := obj.(*Post)
+	post.Title = strings.ToUpper(post.Title)
+	return s.record("BeforeCreate")
+}
+
+func (s *hookedPostSource) AfterCreate(obj interface{}, req Request) error {
+	return s.record(fmt.Sprintf("AfterCreate %s", obj.(*Post).ID))
+}
+
+func (s *hookedPostSource) BeforeUpdate(oldObj, newObj interface{}, req Request) error {
+	newObj.(*Post).Title += " (edited)"
+	return s.record(fmt.Sprintf("BeforeUpdate %s -> %s", oldObj.(*Post).Title, newObj.(*Post).Title))
+}
+
+func (s *hookedPostSource) AfterUpdate(obj interface{}, req Request) error {
+	return s.record("AfterUpdate")
+}
+
+func (s *hookedPostSource) BeforeDelete(id string, req Request) error {
+	return s.record("BeforeDelete " + id)
+}
+
+func (s *hookedPostSource) AfterDelete(id string, req Request) error {
+	return s.record("AfterDelete " + id)
+}
+
+type auditHook struct {
+	calls *[]string
+}
+
+func (a auditHook) AfterDelete(id string, req Request) error {
+	*a.calls = append(*a.calls, "audit "+id)
+	return nil
+}
+
+var _ = Describe("Lifecycle hooks", func() {
+	var (
+		api    *API
+		rec    *httptest.ResponseRecorder
+		source *hookedPostSource
+	)
+
+	BeforeEach(func() {
+		source = &hookedPostSource{fixtureSource: &fixtureSource{map[string]*Post{
+			"1": {ID: "1", Title: "Hello, World!"},
+		}, false}}
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		rec = httptest.NewRecorder()
+	})
+
+	request := func(method, url, body string) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+	}
+
+	It("calls the create hooks around Create", func() {
+		api.AddResource(Post{}, source)
+		request("POST", "/v1/posts", `{"data": {"type": "posts", "attributes": {"title": "new"}}}`)
+		Expect(rec.Code).To(Equal(http.StatusCreated))
+		Expect(source.posts["2"].Title).To(Equal("NEW"))
+		Expect(source.calls).To(Equal([]string{"BeforeCreate", "AfterCreate 2"}))
+	})
+
+	It("aborts Create if a before hook fails", func() {
+		api.AddResource(Post{}, source)
+		source.abort = "BeforeCreate"
+		request("POST", "/v1/posts", `{"data": {"type": "posts", "attributes": {"title": "new"}}}`)
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+		Expect(rec.Body.String()).To(ContainSubstring("Forbidden by BeforeCreate"))
+		Expect(source.posts).To(HaveLen(1))
+	})
+
+	It("calls the update hooks with the old and the new object", func() {
+		api.AddResource(Post{}, source)
+		request("PATCH", "/v1/posts/1", `{"data": {"type": "posts", "id": "1", "attributes": {"title": "Changed"}}}`)
+		Expect(rec.Code).To(Equal(http.StatusNoContent))
+		Expect(source.posts["1"].Title).To(Equal("Changed (edited)"))
+		Expect(source.calls).To(Equal([]string{"BeforeUpdate Hello, World! -> Changed (edited)", "AfterUpdate"}))
+	})
+
+	It("passes a copy of the old object for pointer resources", func() {
+		source.pointers = true
+		api.AddResource(&Post{}, source)
+		request("PATCH", "/v1/posts/1", `{"data": {"type": "posts", "id": "1", "attributes": {"title": "Changed"}}}`)
+		Expect(rec.Code).To(Equal(http.StatusNoContent))
+		Expect(source.calls).To(Equal([]string{"BeforeUpdate Hello, World! -> Changed (edited)", "AfterUpdate"}))
+	})
+
+	It("aborts Update if a before hook fails", func() {
+		api.AddResource(Post{}, source)
+		source.abort = "BeforeUpdate Hello, World! -> Changed (edited)"
+		request("PATCH", "/v1/posts/1", `{"data": {"type": "posts", "id": "1", "attributes": {"title": "Changed"}}}`)
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+		Expect(source.posts["1"].Title).To(Equal("Hello, World!"))
+	})
+
+	It("calls the update hooks for relationship writes", func() {
+		api.AddResource(Post{}, source)
+		request("PATCH", "/v1/posts/1/relationships/author", `{"data": {"type": "users", "id": "2"}}`)
+		Expect(rec.Code).To(Equal(http.StatusNoContent))
+		Expect(source.posts["1"].Author.ID).To(Equal("2"))
+
+		rec = httptest.NewRecorder()
+		request("POST", "/v1/posts/1/relationships/comments", `{"data": [{"type": "comments", "id": "3"}]}`)
+		Expect(rec.Code).To(Equal(http.StatusNoContent))
+		Expect(source.posts["1"].Comments).To(Equal([]Comment{{ID: "3"}}))
+
+		Expect(source.calls).To(Equal([]string{
+			"BeforeUpdate Hello, World! -> Hello, World! (edited)", "AfterUpdate",
+			"BeforeUpdate Hello, World! (edited) -> Hello, World! (edited) (edited)", "AfterUpdate",
+		}))
+	})
+
+	It("aborts relationship writes if a before hook fails", func() {
+		api.AddResource(Post{}, source)
+		source.abort = "BeforeUpdate Hello, World! -> Hello, World! (edited)"
+		request("PATCH", "/v1/posts/1/relationships/author", `{"data": {"type": "users", "id": "2"}}`)
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+		Expect(rec.Body.String()).To(ContainSubstring("Forbidden by BeforeUpdate"))
+		Expect(source.posts["1"].Author).To(BeNil())
+
+		rec = httptest.NewRecorder()
+		request("DELETE", "/v1/posts/1/relationships/comments", `{"data": [{"type": "comments", "id": "3"}]}`)
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+		Expect(source.calls).To(HaveLen(2))
+	})
+
+	It("calls the delete hooks and the hooks of the resource", func() {
+		calls := []string{}
+		api.AddResource(Post{}, source).AddHooks(auditHook{calls: &calls})
+		request("DELETE", "/v1/posts/1", "")
+		Expect(rec.Code).To(Equal(http.StatusNoContent))
+		Expect(source.calls).To(Equal([]string{"BeforeDelete 1", "AfterDelete 1"}))
+		Expect(calls).To(Equal([]string{"audit 1"}))
+	})
+
+	It("aborts Delete if a before hook fails", func() {
+		calls := []string{}
+		api.AddResource(Post{}, source).AddHooks(auditHook{calls: &calls})
+		source.abort = "BeforeDelete 1"
+		request("DELETE", "/v1/posts/1", "")
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+		Expect(source.posts).To(HaveKey("1"))
+		Expect(calls).To(BeEmpty())
+	})
+
+	It("calls the hooks for atomic operations", func() {
+		api.AddResource(Post{}, source)
+		api.EnableAtomicOperations()
+		req, err := http.NewRequest("POST", "/v1/operations", strings.NewReader(`{"atomic:operations": [{"op": "remove", "ref": {"type": "posts", "id": "1"}}]}`))
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Content-Type", defaultContentTypHeader+`; ext="`+atomicExtension+`"`)
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusNoContent))
+		Expect(source.calls).To(Equal([]string{"BeforeDelete 1", "AfterDelete 1"}))
+	})
+})
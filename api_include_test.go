@@ -0,0 +1,112 @@
+package api2go
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Include query parameter", func() {
+	var (
+		api      *API
+		rec      *httptest.ResponseRecorder
+		included func() []string
+	)
+
+	BeforeEach(func() {
+		source := &fixtureSource{map[string]*Post{
+			"1": {
+				ID:       "1",
+				Title:    "Hello, World!",
+				Author:   &User{ID: "1", Name: "Dieter"},
+				Comments: []Comment{{ID: "1", Value: "This is a stupid post!"}},
+			},
+		}, false}
+
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		api.AddResource(Post{}, source)
+		api.AddResource(User{}, &userSource{})
+		api.AddResource(Comment{}, &commentSource{})
+		rec = httptest.NewRecorder()
+
+		included = func() []string {
+			var document struct {
+				Included []struct {
+					Type string `json:"type"`
+					ID   string `json:"id"`
+				} `json:"included"`
+			}
+			Expect(json.Unmarshal(rec.Body.Bytes(), &document)).To(Succeed())
+
+			result := []string{}
+			for _, data := range document.Included {
+				result = append(result, data.Type+":"+data.ID)
+			}
+			return result
+		}
+	})
+
+	It("includes all referenced structs without include parameter", func() {
+		req, err := http.NewRequest("GET", "/v1/posts/1", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(included()).To(Equal([]string{"users:1", "comments:1"}))
+	})
+
+	It("only includes the requested relationships for single objects", func() {
+		req, err := http.NewRequest("GET", "/v1/posts/1?include=comments", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(included()).To(Equal([]string{"comments:1"}))
+	})
+
+	It("only includes the requested relationships for collections", func() {
+		req, err := http.NewRequest("GET", "/v1/posts?include=author", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(included()).To(Equal([]string{"users:1"}))
+	})
+
+	It("includes nothing with an empty include parameter", func() {
+		req, err := http.NewRequest("GET", "/v1/posts/1?include=", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(included()).To(BeEmpty())
+	})
+
+	It("passes the parsed include tree to the source", func() {
+		req, err := http.NewRequest("GET", "/v1/posts/1?include=author,comments", nil)
+		Expect(err).ToNot(HaveOccurred())
+		request := buildRequest(&APIContext{}, req)
+		Expect(request.Include.Paths()).To(Equal([]string{"author", "comments"}))
+	})
+
+	It("rejects unknown relationship paths", func() {
+		req, err := http.NewRequest("GET", "/v1/posts/1?include=author.posts,bananas.peel,foo", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+
+		var document HTTPError
+		Expect(json.Unmarshal(rec.Body.Bytes(), &document)).To(Succeed())
+		Expect(document.Errors).To(HaveLen(3))
+		titles := []string{}
+		for _, e := range document.Errors {
+			Expect(e.Code).To(Equal(codeInvalidQueryInclude))
+			Expect(e.Source.Parameter).To(Equal("include"))
+			titles = append(titles, e.Title)
+		}
+		Expect(titles).To(Equal([]string{
+			`Relationship path "author.posts" does not exist for type "posts"`,
+			`Relationship path "bananas.peel" does not exist for type "posts"`,
+			`Relationship path "foo" does not exist for type "posts"`,
+		}))
+	})
+})
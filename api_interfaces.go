@@ -70,6 +70,22 @@ type PaginatedFindAll interface {
 	PaginatedFindAll(req Request) (totalCount uint, response Responder, err error)
 }
 
+// Cursors contains the opaque cursors returned by CursorPaginatedFindAll. Leave
+// Next or Prev empty if there is no next or previous page.
+type Cursors struct {
+	Next string
+	Prev string
+}
+
+// The CursorPaginatedFindAll interface can be optionally implemented to fetch a subset of all records
+// without having to know the total count. It is used for the query parameters
+// page[after], page[before] and page[size], which can be read from `req.Pagination`.
+// The returned cursors are used as page[after] of the next and page[before] of the prev link.
+// If the resource does not implement FindAll, it is also called without any pagination parameters.
+type CursorPaginatedFindAll interface {
+	CursorPaginatedFindAll(req Request) (cursors Cursors, response Responder, err error)
+}
+
 // The FindAll interface can be optionally implemented to fetch all records at once.
 type FindAll interface {
 	// FindAll returns all objects
@@ -84,6 +100,63 @@ type ObjectInitializer interface {
 	InitializeObject(interface{})
 }
 
+// The BeforeCreate interface can be implemented by sources or hooks registered with
+// Resource.AddHooks. BeforeCreate is called with a pointer to the unmarshalled object
+// before it is passed to Create, so it can still be changed. Returning an error, for
+// example an HTTPError, aborts the request.
+type BeforeCreate interface {
+	BeforeCreate(obj interface{}, req Request) error
+}
+
+// The AfterCreate interface is called after Create succeeded with the result of the
+// response or, if the response has no result, the object that was passed to Create.
+// Returning an error responds with that error, the object has been created anyway.
+type AfterCreate interface {
+	AfterCreate(obj interface{}, req Request) error
+}
+
+// The BeforeUpdate interface is called before Update with pointers to a copy of the
+// object returned by FindOne and to the object with the changes of the request applied.
+// newObj can still be changed, returning an error aborts the request. It is also called for
+// writes to relationships.
+type BeforeUpdate interface {
+	BeforeUpdate(oldObj, newObj interface{}, req Request) error
+}
+
+// The AfterUpdate interface is called after Update succeeded with the result of the
+// response or, if the response has no result, the object that was passed to Update.
+type AfterUpdate interface {
+	AfterUpdate(obj interface{}, req Request) error
+}
+
+// The BeforeDelete interface is called before Delete, returning an error aborts the
+// request.
+type BeforeDelete interface {
+	BeforeDelete(id string, req Request) error
+}
+
+// The AfterDelete interface is called after Delete succeeded.
+type AfterDelete interface {
+	AfterDelete(id string, req Request) error
+}
+
+// The AtomicTransactor interface can be optionally implemented by resources that take part
+// in atomic operations (see API.EnableAtomicOperations). Begin is called before the first
+// operation on the resource, Commit after all operations of the request succeeded and
+// Rollback if any of them failed. Use `req.Context` to share the transaction with the
+// Create, Update and Delete calls of the same request.
+//
+// The operations are only atomic within one transactor. The transactors of a request
+// are committed one after another in the order they were begun; if a Commit fails, that
+// transactor and all following ones are rolled back, but the ones committed before stay
+// committed. Let the sources of all resources share one transactor, for example one
+// database transaction, if a request must be atomic across resources.
+type AtomicTransactor interface {
+	Begin(req Request) error
+	Commit(req Request) error
+	Rollback(req Request) error
+}
+
 // URLResolver allows you to implement a static
 // way to return a baseURL for all incoming
 // requests for one api2go instance.
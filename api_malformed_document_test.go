@@ -0,0 +1,141 @@
+package api2go
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Malformed request documents", func() {
+	var (
+		api    *API
+		rec    *httptest.ResponseRecorder
+		source *ticketSource
+	)
+
+	BeforeEach(func() {
+		source = &ticketSource{tickets: map[string]Ticket{"1": {ID: "1", Title: "Bug", Priority: 2}}}
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		api.AddResource(Ticket{}, source)
+		rec = httptest.NewRecorder()
+	})
+
+	request := func(method, url, body string) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+	}
+
+	It("returns 409 for a type that does not match the endpoint", func() {
+		request("POST", "/v1/tickets", `{"data": {"type": "posts", "attributes": {"title": "New", "priority": 3}}}`)
+		Expect(rec.Code).To(Equal(http.StatusConflict))
+		Expect(rec.Body.String()).To(MatchJSON(`{"errors": [
+			{"status": "409", "title": "Type posts in JSON does not match target struct type tickets", "source": {"pointer": "/data/type"}}
+		]}`))
+		Expect(source.tickets).ToNot(HaveKey("2"))
+	})
+
+	It("returns 409 for a type mismatch on update", func() {
+		request("PATCH", "/v1/tickets/1", `{"data": {"type": "posts", "id": "1", "attributes": {"priority": 3}}}`)
+		Expect(rec.Code).To(Equal(http.StatusConflict))
+		Expect(rec.Body.String()).To(ContainSubstring(`"pointer":"/data/type"`))
+		Expect(source.tickets["1"].Priority).To(Equal(2))
+	})
+
+	It("returns 400 with the offset of invalid JSON", func() {
+		request("POST", "/v1/tickets", `{"data": {"type": "tickets",}}`)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(MatchJSON(`{"errors": [
+			{"status": "400", "title": "invalid character '}' looking for beginning of object key string", "detail": "invalid JSON at offset 29"}
+		]}`))
+	})
+
+	It("returns 400 for a document without data", func() {
+		request("POST", "/v1/tickets", `{"meta": {}}`)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring(`"pointer":"/data"`))
+	})
+
+	It("returns 400 for an array sent to a single resource endpoint", func() {
+		request("POST", "/v1/tickets", `{"data": [{"type": "tickets", "attributes": {"title": "New", "priority": 3}}]}`)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring(`"pointer":"/data"`))
+	})
+
+	It("points to attributes with a wrong type", func() {
+		request("POST", "/v1/tickets", `{"data": {"type": "tickets", "attributes": {"title": "New", "priority": "high"}}}`)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring(`"source":{"pointer":"/data/attributes/priority"}`))
+	})
+
+	It("points to members of the resource object with a wrong type", func() {
+		request("POST", "/v1/tickets", `{"data": {"type": 5, "attributes": {"title": "New", "priority": 3}}}`)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring(`"source":{"pointer":"/data/type"}`))
+	})
+
+	It("points to relationships with an invalid shape", func() {
+		request("POST", "/v1/tickets", `{"data": {"type": "tickets", "attributes": {"title": "New", "priority": 3},
+			"relationships": {"assignee": {"data": "1"}}}}`)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(MatchJSON(`{"errors": [
+			{"status": "400", "title": "Invalid json for relationship data array/object", "source": {"pointer": "/data/relationships/assignee"}}
+		]}`))
+	})
+
+	It("points to relationships the resource does not support", func() {
+		request("POST", "/v1/tickets", `{"data": {"type": "tickets", "attributes": {"title": "New", "priority": 3},
+			"relationships": {"assignee": {"data": {"type": "users", "id": "1"}}}}}`)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring(`"source":{"pointer":"/data/relationships/assignee"}`))
+	})
+
+	Context("when writing relationships", func() {
+		var posts *fixtureSource
+
+		BeforeEach(func() {
+			posts = &fixtureSource{map[string]*Post{"1": {ID: "1", Title: "Hello, World!"}}, false}
+			api.AddResource(Post{}, posts)
+		})
+
+		It("returns 400 with the offset of invalid JSON", func() {
+			request("PATCH", "/v1/posts/1/relationships/author", `{not json`)
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(rec.Body.String()).To(ContainSubstring(`"detail":"invalid JSON at offset 2"`))
+		})
+
+		It("returns 400 for a document without data", func() {
+			request("PATCH", "/v1/posts/1/relationships/author", `{"meta": {}}`)
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(rec.Body.String()).To(ContainSubstring(`"source":{"pointer":"/data"}`))
+		})
+
+		It("returns 400 for an object sent to a to-many relationship", func() {
+			request("PATCH", "/v1/posts/1/relationships/comments", `{"data": {"type": "comments", "id": "1"}}`)
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(rec.Body.String()).To(MatchJSON(`{"errors": [
+				{"status": "400", "title": "There is no to-one relationship with the name comments", "source": {"pointer": "/data"}}
+			]}`))
+		})
+
+		It("points to invalid entries of the data array", func() {
+			request("PATCH", "/v1/posts/1/relationships/comments", `{"data": [{"type": "comments", "id": "1"}, 1]}`)
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(rec.Body.String()).To(ContainSubstring(`"source":{"pointer":"/data/1"}`))
+
+			rec = httptest.NewRecorder()
+			request("POST", "/v1/posts/1/relationships/comments", `{"data": [1]}`)
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(rec.Body.String()).To(ContainSubstring(`"source":{"pointer":"/data/0"}`))
+		})
+
+		It("returns 400 for an object sent to remove from a to-many relationship", func() {
+			request("DELETE", "/v1/posts/1/relationships/comments", `{"data": {"type": "comments", "id": "1"}}`)
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(rec.Body.String()).To(ContainSubstring(`"source":{"pointer":"/data"}`))
+		})
+	})
+})
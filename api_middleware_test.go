@@ -0,0 +1,199 @@
+package api2go
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type panickingCommentSource struct {
+	commentSource
+}
+
+func (s *panickingCommentSource) FindAll(req Request) (Responder, error) {
+	panic("boom")
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+var _ = Describe("Middleware", func() {
+	var (
+		api    *API
+		rec    *httptest.ResponseRecorder
+		source *sortableCommentSource
+		calls  []string
+	)
+
+	BeforeEach(func() {
+		source = &sortableCommentSource{}
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		api.AddResource(Comment{}, source)
+		rec = httptest.NewRecorder()
+		calls = nil
+	})
+
+	request := func(method, url string) {
+		req, err := http.NewRequest(method, url, nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+	}
+
+	tracing := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(c APIContexter, w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name+" before")
+				next(c, w, r)
+				calls = append(calls, name+" after")
+			}
+		}
+	}
+
+	It("runs middlewares in the order they have been registered", func() {
+		api.Use(tracing("first"))
+		api.UseMiddleware(func(c APIContexter, w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, "legacy")
+		})
+		api.Use(tracing("second"))
+
+		request("GET", "/v1/comments")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(calls).To(Equal([]string{"first before", "legacy", "second before", "second after", "first after"}))
+		Expect(source.lastRequest).ToNot(BeNil())
+	})
+
+	It("can reject requests without calling next", func() {
+		api.Use(func(next HandlerFunc) HandlerFunc {
+			return func(c APIContexter, w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") == "" {
+					handleError(NewHTTPError(nil, "Unauthorized", http.StatusUnauthorized), w, r, defaultContentTypHeader)
+					return
+				}
+				next(c, w, r)
+			}
+		})
+
+		request("GET", "/v1/comments")
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		Expect(source.lastRequest).To(BeNil())
+	})
+
+	It("can wrap the response writer", func() {
+		var recorder *statusRecorder
+		api.Use(func(next HandlerFunc) HandlerFunc {
+			return func(c APIContexter, w http.ResponseWriter, r *http.Request) {
+				recorder = &statusRecorder{ResponseWriter: w}
+				next(c, recorder, r)
+			}
+		})
+
+		request("GET", "/v1/comments?sort=unknown")
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.status).To(Equal(http.StatusBadRequest))
+	})
+
+	It("can replace the request", func() {
+		api.Use(func(next HandlerFunc) HandlerFunc {
+			return func(c APIContexter, w http.ResponseWriter, r *http.Request) {
+				r.URL.RawQuery = "sort=-value"
+				next(c, w, r)
+			}
+		})
+
+		request("GET", "/v1/comments")
+		Expect(source.lastRequest.Sort).To(Equal([]SortField{{Name: "value", Descending: true}}))
+	})
+
+	It("can recover panics of the generated routes", func() {
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		api.AddResource(Comment{}, &panickingCommentSource{})
+		api.Use(func(next HandlerFunc) HandlerFunc {
+			return func(c APIContexter, w http.ResponseWriter, r *http.Request) {
+				defer func() {
+					if recovered := recover(); recovered != nil {
+						handleError(NewHTTPError(nil, "Internal Server Error", http.StatusInternalServerError), w, r, defaultContentTypHeader)
+					}
+				}()
+
+				next(c, w, r)
+			}
+		})
+
+		request("GET", "/v1/comments")
+		Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("wraps OPTIONS routes", func() {
+		api.Use(tracing("only"))
+
+		request("OPTIONS", "/v1/comments")
+		Expect(rec.Code).To(Equal(http.StatusNoContent))
+		Expect(calls).To(Equal([]string{"only before", "only after"}))
+	})
+})
+
+var _ = Describe("Resource middleware", func() {
+	var (
+		api   *API
+		rec   *httptest.ResponseRecorder
+		calls []string
+	)
+
+	BeforeEach(func() {
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		rec = httptest.NewRecorder()
+		calls = nil
+	})
+
+	request := func(method, url string) {
+		req, err := http.NewRequest(method, url, nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+	}
+
+	It("returns the registered resource", func() {
+		resource := api.AddResource(Comment{}, &sortableCommentSource{})
+		Expect(resource.Name()).To(Equal("comments"))
+	})
+
+	It("only runs resource middlewares for routes of the resource", func() {
+		api.AddResource(Post{}, &fixtureSource{map[string]*Post{"1": {ID: "1"}}, false}).
+			UseMiddleware(func(c APIContexter, w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, "posts "+r.URL.Path)
+			})
+		api.AddResource(Comment{}, &sortableCommentSource{})
+
+		request("GET", "/v1/comments")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(calls).To(BeEmpty())
+
+		request("GET", "/v1/posts/1")
+		request("GET", "/v1/posts/1/relationships/comments")
+		Expect(calls).To(Equal([]string{"posts /v1/posts/1", "posts /v1/posts/1/relationships/comments"}))
+	})
+
+	It("runs resource middlewares after the api middlewares", func() {
+		api.UseMiddleware(func(c APIContexter, w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, "api")
+		})
+		api.AddResource(Comment{}, &commentSource{}).Use(func(next HandlerFunc) HandlerFunc {
+			return func(c APIContexter, w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, "comments")
+				w.WriteHeader(http.StatusForbidden)
+			}
+		})
+
+		request("GET", "/v1/comments")
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+		Expect(calls).To(Equal([]string{"api", "comments"}))
+	})
+})
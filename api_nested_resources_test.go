@@ -0,0 +1,242 @@
+package api2go
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/manyminds/api2go/jsonapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type Store struct {
+	ID string `json:"-"`
+}
+
+func (s Store) GetID() jsonapi.Identifier {
+	return jsonapi.Identifier{ID: s.ID}
+}
+
+func (s *Store) SetID(ID jsonapi.Identifier) error {
+	s.ID = ID.ID
+	return nil
+}
+
+func (s Store) GetReferences() []jsonapi.Reference {
+	return []jsonapi.Reference{{Name: "devices", Type: "devices"}}
+}
+
+func (s Store) GetReferencedIDs() []jsonapi.ReferenceID {
+	return []jsonapi.ReferenceID{}
+}
+
+type storeSource struct{}
+
+func (s *storeSource) FindOne(id string, req Request) (Responder, error) {
+	return &Response{Res: Store{ID: id}}, nil
+}
+
+// Device is nested below stores, StoreID is only set if the device belongs to another
+// store than the one of the request
+type Device struct {
+	ID      string `json:"-"`
+	StoreID string `json:"-"`
+	Name    string `json:"name"`
+}
+
+func (d Device) GetID() jsonapi.Identifier {
+	return jsonapi.Identifier{ID: d.ID}
+}
+
+func (d *Device) SetID(ID jsonapi.Identifier) error {
+	d.ID = ID.ID
+	return nil
+}
+
+func (d Device) GetParentIDs() map[string]string {
+	if d.StoreID == "" {
+		return nil
+	}
+
+	return map[string]string{"storeID": d.StoreID}
+}
+
+func (d Device) GetReferences() []jsonapi.Reference {
+	return []jsonapi.Reference{{Name: "store", Type: "stores"}}
+}
+
+func (d Device) GetReferencedIDs() []jsonapi.ReferenceID {
+	return []jsonapi.ReferenceID{}
+}
+
+type deviceSource struct {
+	devices     map[string]*Device
+	lastRequest *Request
+}
+
+func (s *deviceSource) FindAll(req Request) (Responder, error) {
+	s.lastRequest = &req
+
+	devices := []Device{}
+	for _, device := range s.devices {
+		devices = append(devices, *device)
+	}
+
+	return &Response{Res: devices}, nil
+}
+
+func (s *deviceSource) FindOne(id string, req Request) (Responder, error) {
+	s.lastRequest = &req
+
+	device, ok := s.devices[id]
+	if !ok {
+		return &Response{}, NewHTTPError(nil, "device not found", http.StatusNotFound)
+	}
+
+	return &Response{Res: *device}, nil
+}
+
+func (s *deviceSource) Create(obj interface{}, req Request) (Responder, error) {
+	s.lastRequest = &req
+
+	device := obj.(Device)
+	device.ID = "2"
+	s.devices[device.ID] = &device
+
+	return &Response{Res: device, Code: http.StatusCreated}, nil
+}
+
+var _ = Describe("Nested resources", func() {
+	var (
+		api     *API
+		rec     *httptest.ResponseRecorder
+		devices *deviceSource
+	)
+
+	BeforeEach(func() {
+		devices = &deviceSource{devices: map[string]*Device{"1": {ID: "1", Name: "Scanner"}}}
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		rec = httptest.NewRecorder()
+	})
+
+	request := func(method, url, body string) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+	}
+
+	links := func() map[string]string {
+		var document struct {
+			Data struct {
+				Relationships map[string]struct {
+					Links map[string]string `json:"links"`
+				} `json:"relationships"`
+			} `json:"data"`
+		}
+		Expect(json.Unmarshal(rec.Body.Bytes(), &document)).To(Succeed())
+		return document.Data.Relationships["store"].Links
+	}
+
+	Context("when registering a resource below a parent", func() {
+		BeforeEach(func() {
+			api.AddResource(Store{}, &storeSource{}, IDParam("storeID"), DisableRelationshipOperations("devices", OperationReadRelated))
+			api.AddResource(Device{}, devices, Under("/stores/:storeID/"))
+		})
+
+		It("generates the routes below the parent", func() {
+			var paths []string
+			for _, route := range api.Routes() {
+				if route.Resource == "devices" && route.Relationship == "" {
+					paths = append(paths, route.Method+" "+route.Path)
+				}
+			}
+
+			Expect(paths).To(Equal([]string{
+				"OPTIONS /v1/stores/:storeID/devices",
+				"GET /v1/stores/:storeID/devices",
+				"OPTIONS /v1/stores/:storeID/devices/:id",
+				"GET /v1/stores/:storeID/devices/:id",
+				"POST /v1/stores/:storeID/devices",
+			}))
+		})
+
+		It("reads the parent with the renamed id parameter", func() {
+			request("GET", "/v1/stores/42", "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Body.String()).To(ContainSubstring(`"id":"42"`))
+			Expect(rec.Body.String()).To(ContainSubstring(`"related":"/v1/stores/42/devices"`))
+		})
+
+		It("passes the parent ids to the source", func() {
+			request("GET", "/v1/stores/42/devices", "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(devices.lastRequest.ParentIDs).To(Equal(map[string]string{"storeID": "42"}))
+
+			request("GET", "/v1/stores/42/devices/1", "")
+			Expect(devices.lastRequest.ParentIDs).To(Equal(map[string]string{"storeID": "42"}))
+		})
+
+		It("uses the parent ids of the request in links", func() {
+			request("GET", "/v1/stores/42/devices/1", "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+
+			Expect(links()).To(Equal(map[string]string{
+				"self":    "/v1/stores/42/devices/1/relationships/store",
+				"related": "/v1/stores/42/devices/1/store",
+			}))
+		})
+
+		It("prefers the parent ids of the resource in links", func() {
+			devices.devices["1"].StoreID = "a/b"
+
+			request("GET", "/v1/stores/42/devices/1", "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+
+			Expect(links()["related"]).To(Equal("/v1/stores/a%2Fb/devices/1/store"))
+		})
+
+		It("leaves out the links if the parent ids are unknown", func() {
+			info := information{prefix: testPrefix, resolver: NewStaticResolver(""), api: api}
+			Expect(info.GetResourcePath("devices", Device{ID: "1"})).To(BeEmpty())
+
+			document, err := jsonapi.MarshalToStruct(Device{ID: "1"}, info)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(document.Data.DataObject.Relationships["store"].Links).To(BeNil())
+		})
+
+		It("rejects a second registration of the resource", func() {
+			Expect(func() { api.AddResource(Device{}, devices, Under("admin")) }).To(Panic())
+		})
+
+		It("sets the location of created resources below the parent", func() {
+			request("POST", "/v1/stores/42/devices", `{"data": {"type": "devices", "attributes": {"name": "Printer"}}}`)
+			Expect(rec.Code).To(Equal(http.StatusCreated))
+			Expect(rec.Header().Get("Location")).To(Equal("/v1/stores/42/devices/2"))
+			Expect(devices.lastRequest.ParentIDs).To(Equal(map[string]string{"storeID": "42"}))
+		})
+
+		It("documents the parent parameters in the OpenAPI document", func() {
+			document := api.OpenAPI()
+			Expect(document.Paths).To(HaveKey("/v1/stores/{storeID}"))
+			Expect(document.Paths).To(HaveKey("/v1/stores/{storeID}/devices"))
+			Expect(document.Paths).To(HaveKey("/v1/stores/{storeID}/devices/{id}/relationships/store"))
+
+			read := document.Paths["/v1/stores/{storeID}/devices/{id}"]["get"]
+			Expect(read.Parameters[0]).To(Equal(OpenAPIParameter{Name: "storeID", In: "path", Required: true, Schema: OpenAPISchema{"type": "string"}}))
+			Expect(read.Parameters[1]).To(Equal(OpenAPIParameter{Ref: "#/components/parameters/id"}))
+			Expect(document.Paths["/v1/stores/{storeID}"]["get"].Parameters[0].Name).To(Equal("storeID"))
+		})
+	})
+
+	It("namespaces resources below a path without parameters", func() {
+		api.AddResource(Device{}, devices, Under("admin"))
+
+		request("GET", "/v1/admin/devices/1", "")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(devices.lastRequest.ParentIDs).To(BeNil())
+
+		Expect(links()["related"]).To(Equal("/v1/admin/devices/1/store"))
+	})
+})
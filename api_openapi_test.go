@@ -0,0 +1,156 @@
+package api2go
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type openAPIEmbedded struct {
+	Created time.Time `json:"created"`
+}
+
+type openAPIAttributes struct {
+	openAPIEmbedded
+	ID       string             `json:"-"`
+	Name     string             `json:"name"`
+	Count    int64              `json:"count,string"`
+	Rating   *float64           `json:"rating,omitempty"`
+	Tags     []string           `json:"tags"`
+	Labels   map[string]bool    `json:"labels"`
+	Parent   *openAPIAttributes `json:"parent"`
+	Raw      []byte             `json:"raw"`
+	Untagged int32
+	private  string
+}
+
+var _ = Describe("OpenAPI document", func() {
+	var (
+		api      *API
+		document map[string]interface{}
+	)
+
+	lookup := func(path ...string) interface{} {
+		var current interface{} = document
+		for _, key := range path {
+			object, ok := current.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			current = object[key]
+		}
+		return current
+	}
+
+	BeforeEach(func() {
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver("http://localhost"), newTestRouter())
+		api.AddResource(Post{}, &fixtureSource{map[string]*Post{}, false})
+		api.AddResource(User{}, &userSource{})
+		api.AddResource(Comment{}, &filterableCommentSource{})
+		api.SetOpenAPIInfo(OpenAPIInfo{Title: "Blog", Version: "2.0.0"})
+		api.ServeOpenAPI("openapi.json")
+
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/v1/openapi.json", nil)
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Accept", "application/json")
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		document = nil
+		Expect(json.Unmarshal(rec.Body.Bytes(), &document)).To(Succeed())
+	})
+
+	It("serves the document with info and servers", func() {
+		Expect(document["openapi"]).To(Equal("3.1.0"))
+		Expect(lookup("info", "title")).To(Equal("Blog"))
+		Expect(lookup("info", "version")).To(Equal("2.0.0"))
+		Expect(document["servers"]).To(Equal([]interface{}{map[string]interface{}{"url": "http://localhost"}}))
+	})
+
+	It("uses defaults without servers when calling OpenAPI directly", func() {
+		api := NewAPI("v1")
+		result := api.OpenAPI()
+		Expect(result.Info).To(Equal(OpenAPIInfo{Title: "api2go", Version: "1.0.0"}))
+		Expect(result.Servers).To(BeNil())
+		Expect(result.Paths).To(BeEmpty())
+	})
+
+	It("documents the implemented operations of every resource", func() {
+		Expect(lookup("paths", "/v1/posts", "get", "operationId")).To(Equal("posts.list"))
+		Expect(lookup("paths", "/v1/posts", "post", "operationId")).To(Equal("posts.create"))
+		Expect(lookup("paths", "/v1/posts/{id}", "get", "operationId")).To(Equal("posts.read"))
+		Expect(lookup("paths", "/v1/posts/{id}", "patch", "operationId")).To(Equal("posts.update"))
+		Expect(lookup("paths", "/v1/posts/{id}", "delete", "operationId")).To(Equal("posts.delete"))
+		Expect(lookup("paths", "/v1/posts/{id}", "get", "responses", "200", "content", defaultContentTypHeader, "schema", "$ref")).
+			To(Equal("#/components/schemas/postsDocument"))
+		Expect(lookup("paths", "/v1/posts", "post", "requestBody", "content", defaultContentTypHeader, "schema", "$ref")).
+			To(Equal("#/components/schemas/postsCreateDocument"))
+		Expect(lookup("paths", "/v1/posts", "get", "responses", "default", "$ref")).To(Equal("#/components/responses/Error"))
+	})
+
+	It("documents relationship endpoints", func() {
+		Expect(lookup("paths", "/v1/posts/{id}/author", "get", "responses", "200", "content", defaultContentTypHeader, "schema", "$ref")).
+			To(Equal("#/components/schemas/usersDocument"))
+		Expect(lookup("paths", "/v1/posts/{id}/comments", "get", "responses", "200", "content", defaultContentTypHeader, "schema", "$ref")).
+			To(Equal("#/components/schemas/commentsCollectionDocument"))
+		Expect(lookup("paths", "/v1/posts/{id}/bananas", "get", "responses", "200", "content", defaultContentTypHeader, "schema", "$ref")).
+			To(Equal("#/components/schemas/CollectionDocument"))
+		Expect(lookup("paths", "/v1/posts/{id}/relationships/author", "patch", "requestBody", "content", defaultContentTypHeader, "schema", "$ref")).
+			To(Equal("#/components/schemas/ToOneRelationship"))
+		Expect(lookup("paths", "/v1/posts/{id}/relationships/author", "post")).To(BeNil())
+		Expect(lookup("paths", "/v1/posts/{id}/relationships/comments", "post", "operationId")).To(Equal("posts.comments.addToRelationship"))
+		Expect(lookup("paths", "/v1/posts/{id}/relationships/comments", "delete", "operationId")).To(Equal("posts.comments.removeFromRelationship"))
+	})
+
+	It("documents the query parameters of collections", func() {
+		Expect(lookup("paths", "/v1/posts", "get", "parameters")).To(ContainElement(map[string]interface{}{"$ref": "#/components/parameters/include"}))
+		Expect(lookup("paths", "/v1/posts", "get", "parameters")).To(ContainElement(map[string]interface{}{"$ref": "#/components/parameters/pageNumber"}))
+		Expect(lookup("paths", "/v1/users", "get", "parameters")).ToNot(ContainElement(map[string]interface{}{"$ref": "#/components/parameters/include"}))
+
+		names := []interface{}{}
+		for _, parameter := range lookup("paths", "/v1/comments", "get", "parameters").([]interface{}) {
+			names = append(names, parameter.(map[string]interface{})["name"])
+		}
+		Expect(names).To(ContainElement("filter[rating][gte]"))
+		Expect(names).To(ContainElement("filter[value]"))
+		Expect(names).To(ContainElement("filter[value][like]"))
+	})
+
+	It("documents resource schemas with attributes and relationships", func() {
+		Expect(lookup("components", "schemas", "postsAttributes", "properties", "title")).To(Equal(map[string]interface{}{"type": "string"}))
+		Expect(lookup("components", "schemas", "postsResource", "properties", "type", "const")).To(Equal("posts"))
+		Expect(lookup("components", "schemas", "postsResource", "properties", "relationships", "properties", "author", "$ref")).
+			To(Equal("#/components/schemas/ToOneRelationship"))
+		Expect(lookup("components", "schemas", "postsResource", "properties", "relationships", "properties", "comments", "$ref")).
+			To(Equal("#/components/schemas/ToManyRelationship"))
+		Expect(lookup("components", "schemas", "postsUpdateDocument", "properties", "data", "required")).To(Equal([]interface{}{"type", "id"}))
+	})
+
+	It("documents the atomic operations endpoint", func() {
+		Expect(api.OpenAPI().Paths).ToNot(HaveKey("/v1/operations"))
+		api.EnableAtomicOperations()
+		Expect(api.OpenAPI().Paths).To(HaveKey("/v1/operations"))
+	})
+
+	It("reflects attribute schemas from json tags", func() {
+		schema := openAPITypeSchema(reflect.TypeOf(openAPIAttributes{}), map[reflect.Type]bool{})
+		properties := schema["properties"].(map[string]interface{})
+		Expect(properties).To(HaveLen(9))
+		Expect(properties["created"]).To(Equal(OpenAPISchema{"type": "string", "format": "date-time"}))
+		Expect(properties["name"]).To(Equal(OpenAPISchema{"type": "string"}))
+		Expect(properties["count"]).To(Equal(OpenAPISchema{"type": "string"}))
+		Expect(properties["rating"]).To(Equal(OpenAPISchema{"type": []string{"number", "null"}, "format": "double"}))
+		Expect(properties["tags"]).To(Equal(OpenAPISchema{"type": "array", "items": OpenAPISchema{"type": "string"}}))
+		Expect(properties["labels"]).To(Equal(OpenAPISchema{"type": "object", "additionalProperties": OpenAPISchema{"type": "boolean"}}))
+		Expect(properties["parent"]).To(Equal(OpenAPISchema{"type": []string{"object", "null"}}))
+		Expect(properties["raw"]).To(Equal(OpenAPISchema{"type": "string", "format": "byte"}))
+		Expect(properties["Untagged"]).To(Equal(OpenAPISchema{"type": "integer", "format": "int32"}))
+	})
+})
@@ -8,6 +8,10 @@ import (
 	"github.com/manyminds/api2go/routing"
 )
 
+// Middleware wraps the handler of a generated route. It must call next to continue
+// with the following middlewares and the generated route.
+type Middleware func(next HandlerFunc) HandlerFunc
+
 // HandlerFunc for api2go middlewares
 type HandlerFunc func(APIContexter, http.ResponseWriter, *http.Request)
 
@@ -16,10 +20,17 @@ type API struct {
 	ContentType      string
 	router           routing.Routeable
 	info             information
-	resources        []resource
-	middlewares      []HandlerFunc
+	resources        []*resource
+	middlewares      []Middleware
 	contextPool      sync.Pool
 	contextAllocator APIContextAllocatorFunc
+	extensions       []string
+	profiles         []string
+	openAPIInfo      OpenAPIInfo
+	errorHandler     ErrorHandler
+	errorLogger      ErrorLogger
+	compression      *compression
+	routes           []Route
 }
 
 // Handler returns the http.Handler instance for the API.
@@ -37,17 +48,133 @@ func (api *API) SetContextAllocator(allocator APIContextAllocatorFunc) {
 	api.contextAllocator = allocator
 }
 
+// SetErrorHandler replaces DefaultErrorHandler, which converts the errors of the
+// generated routes into the HTTPError that is sent to the client. Use it to map
+// domain errors like sql.ErrNoRows to a 404 or to hide the messages of internal
+// errors. Passing nil restores the default.
+func (api *API) SetErrorHandler(handler ErrorHandler) {
+	api.errorHandler = handler
+}
+
+// SetErrorLogger replaces the standard logger for all errors that occur while
+// handling requests. The logger receives the original error before the ErrorHandler
+// converts it. Passing nil restores the default.
+func (api *API) SetErrorLogger(logger ErrorLogger) {
+	api.errorLogger = logger
+}
+
 // AddResource registers a data source for the given resource
 // At least the CRUD interface must be implemented, all the other interfaces are optional.
 // `resource` should be either an empty struct instance such as `Post{}` or a pointer to
 // a struct such as `&Post{}`. The same type will be used for constructing new elements.
-func (api *API) AddResource(prototype jsonapi.MarshalIdentifier, source interface{}) {
-	api.addResource(prototype, source)
+//
+// The returned Resource can be used to configure the generated routes of this resource only.
+// Options can disable operations or change the paths of relationships, see DisableOperations
+// and RelationshipPath.
+func (api *API) AddResource(prototype jsonapi.MarshalIdentifier, source interface{}, options ...ResourceOption) *Resource {
+	return &Resource{resource: api.addResource(prototype, source, options...)}
+}
+
+// Resource is a registered resource
+type Resource struct {
+	resource *resource
+}
+
+// Name returns the name of the resource as used in the generated routes
+func (r *Resource) Name() string {
+	return r.resource.name
+}
+
+// Use registers middlewares that only wrap the generated routes of this resource. They run
+// after the middlewares of the API, see API.Use.
+func (r *Resource) Use(middleware ...Middleware) *Resource {
+	r.resource.middlewares = append(r.resource.middlewares, middleware...)
+	return r
+}
+
+// AddHooks registers hooks that implement any of the BeforeCreate, AfterCreate,
+// BeforeUpdate, AfterUpdate, BeforeDelete and AfterDelete interfaces for this resource.
+// They are called after the hooks that are implemented by the source itself.
+func (r *Resource) AddHooks(hooks ...interface{}) *Resource {
+	r.resource.hooks = append(r.resource.hooks, hooks...)
+	return r
+}
+
+// SetCachePolicy sets the Cache-Control and Vary headers of all successful reads of this
+// resource. Responders can override it with the CacheHints interface.
+func (r *Resource) SetCachePolicy(policy CachePolicy) *Resource {
+	r.resource.cachePolicy = &policy
+	return r
+}
+
+// UseMiddleware registers middlewares that run before the generated routes of this resource,
+// see API.UseMiddleware.
+func (r *Resource) UseMiddleware(middleware ...HandlerFunc) *Resource {
+	return r.Use(wrapHandlerFuncs(middleware)...)
+}
+
+// EnableAtomicOperations registers the `/operations` endpoint of the JSON:API atomic
+// operations extension (https://jsonapi.org/ext/atomic). The add, update and remove
+// operations of a request are dispatched in order to the registered resources, local ids
+// (`lid`) of added resources can be referenced by later operations.
+// Resources that implement AtomicTransactor can commit or roll back the whole batch.
+func (api *API) EnableAtomicOperations() {
+	api.RegisterExtension(atomicExtension)
+	api.addOperationsRoute()
+}
+
+// EnableCompression compresses the responses of all generated routes, including error
+// responses, with the first of the encoders that is accepted by the client with the
+// highest quality in its Accept-Encoding header. Responses smaller than minSize bytes
+// are sent uncompressed. GzipEncoder is used if no encoders are given.
+func (api *API) EnableCompression(minSize int, encoders ...Encoder) {
+	if len(encoders) == 0 {
+		encoders = []Encoder{GzipEncoder{}}
+	}
+
+	api.compression = &compression{minSize: minSize, encoders: encoders}
+}
+
+// RegisterExtension marks the JSON:API extensions with the given URIs as supported.
+// Requests that apply any other extension with the `ext` media type parameter are
+// rejected with 415 Unsupported Media Type or 406 Not Acceptable.
+func (api *API) RegisterExtension(uri ...string) {
+	api.extensions = appendMissing(api.extensions, uri...)
+}
+
+// RegisterProfile marks the JSON:API profiles with the given URIs as supported.
+// Supported profiles that a client requests are applied to the Content-Type of the
+// response and are available in Request.Profiles, all other profiles are ignored.
+func (api *API) RegisterProfile(uri ...string) {
+	api.profiles = appendMissing(api.profiles, uri...)
 }
 
 // UseMiddleware registers middlewares that implement the api2go.HandlerFunc
 // Middleware is run before any generated routes.
 func (api *API) UseMiddleware(middleware ...HandlerFunc) {
+	api.Use(wrapHandlerFuncs(middleware)...)
+}
+
+// wrapHandlerFuncs converts middlewares that run before the handler into Middlewares
+func wrapHandlerFuncs(handlers []HandlerFunc) []Middleware {
+	result := make([]Middleware, 0, len(handlers))
+	for _, handler := range handlers {
+		result = append(result, func(next HandlerFunc) HandlerFunc {
+			return func(c APIContexter, w http.ResponseWriter, r *http.Request) {
+				handler(c, w, r)
+				next(c, w, r)
+			}
+		})
+	}
+
+	return result
+}
+
+// Use registers middlewares that wrap every generated route. A middleware can stop the
+// request by not calling next, pass another http.ResponseWriter or *http.Request to
+// next, or recover panics of the following handlers. Middlewares registered with Use and
+// UseMiddleware run in the order in which they have been registered.
+func (api *API) Use(middleware ...Middleware) {
 	api.middlewares = append(api.middlewares, middleware...)
 }
 
@@ -110,9 +237,10 @@ func newAPI(prefix string, resolver URLResolver, router routing.Routeable) *API
 		ContentType:      defaultContentTypHeader,
 		router:           router,
 		info:             info,
-		middlewares:      make([]HandlerFunc, 0),
+		middlewares:      make([]Middleware, 0),
 		contextAllocator: nil,
 	}
+	api.info.api = api
 
 	api.contextPool.New = func() interface{} {
 		if api.contextAllocator != nil {
@@ -0,0 +1,174 @@
+package api2go
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Resource options", func() {
+	var (
+		api    *API
+		rec    *httptest.ResponseRecorder
+		source *fixtureSource
+	)
+
+	BeforeEach(func() {
+		source = &fixtureSource{map[string]*Post{
+			"1": {ID: "1", Title: "Hello, World!", Comments: []Comment{{ID: "1"}}},
+		}, false}
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		rec = httptest.NewRecorder()
+	})
+
+	request := func(method, url, body string) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+		if strings.HasSuffix(url, "/operations") {
+			req.Header.Set("Content-Type", `application/vnd.api+json; ext="https://jsonapi.org/ext/atomic"`)
+		}
+		api.Handler().ServeHTTP(rec, req)
+	}
+
+	routesOf := func(relationship string) []string {
+		var result []string
+		for _, route := range api.Routes() {
+			if route.Relationship == relationship {
+				result = append(result, route.Method+" "+route.Path)
+			}
+		}
+		return result
+	}
+
+	It("lists the methods registered for the path in the Allow header", func() {
+		api.AddResource(Post{}, source)
+
+		for _, route := range api.Routes() {
+			if route.Method != http.MethodOptions || route.Resource != "posts" || route.Relationship != "" {
+				continue
+			}
+
+			var methods []string
+			for _, other := range api.Routes() {
+				if other.Path == route.Path {
+					methods = append(methods, other.Method)
+				}
+			}
+
+			rec = httptest.NewRecorder()
+			request("OPTIONS", strings.Replace(route.Path, ":id", "1", 1), "")
+			Expect(strings.Split(rec.Header().Get("Allow"), ",")).To(ConsistOf(methods), route.Path)
+		}
+
+		rec = httptest.NewRecorder()
+		request("OPTIONS", "/v1/posts", "")
+		Expect(rec.Header().Get("Allow")).ToNot(ContainSubstring("PATCH"))
+	})
+
+	Context("when disabling operations", func() {
+		BeforeEach(func() {
+			api.AddResource(Post{}, source, DisableOperations(OperationDelete, OperationList))
+		})
+
+		It("does not generate their routes", func() {
+			request("DELETE", "/v1/posts/1", "")
+			Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+			Expect(source.posts).To(HaveKey("1"))
+
+			for _, route := range api.Routes() {
+				Expect(route.Kind).ToNot(Equal(RouteDelete))
+				Expect(route.Kind).ToNot(Equal(RouteIndex))
+			}
+		})
+
+		It("leaves out their methods in the Allow header", func() {
+			request("OPTIONS", "/v1/posts/1", "")
+			Expect(rec.Code).To(Equal(http.StatusNoContent))
+			Expect(rec.Header().Get("Allow")).To(Equal("OPTIONS,GET,PATCH"))
+
+			rec = httptest.NewRecorder()
+			request("OPTIONS", "/v1/posts", "")
+			Expect(rec.Header().Get("Allow")).To(Equal("OPTIONS,POST"))
+		})
+
+		It("leaves them out of the OpenAPI document", func() {
+			document := api.OpenAPI()
+			Expect(document.Paths["/v1/posts"]).ToNot(HaveKey("get"))
+			Expect(document.Paths["/v1/posts"]).To(HaveKey("post"))
+			Expect(document.Paths["/v1/posts/{id}"]).ToNot(HaveKey("delete"))
+		})
+
+		It("rejects them in atomic operations", func() {
+			api.EnableAtomicOperations()
+			request("POST", "/v1/operations", `{"atomic:operations": [{"op": "remove", "ref": {"type": "posts", "id": "1"}}]}`)
+			Expect(rec.Code).To(Equal(http.StatusForbidden))
+			Expect(rec.Body.String()).To(ContainSubstring("Operation delete is disabled for resource posts"))
+			Expect(source.posts).To(HaveKey("1"))
+		})
+	})
+
+	It("makes relationships read-only", func() {
+		api.AddResource(Post{}, source, DisableOperations(RelationshipWriteOperations...))
+
+		Expect(routesOf("comments")).To(Equal([]string{
+			"GET /v1/posts/:id/relationships/comments",
+			"GET /v1/posts/:id/comments",
+		}))
+
+		request("PATCH", "/v1/posts/1/relationships/author", `{"data": {"type": "users", "id": "2"}}`)
+		Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(source.posts["1"].Author).To(BeNil())
+	})
+
+	It("disables operations of a single relationship", func() {
+		api.AddResource(Post{}, source, DisableRelationshipOperations("comments", OperationAddToRelationship, OperationReadRelated))
+
+		Expect(routesOf("comments")).To(Equal([]string{
+			"GET /v1/posts/:id/relationships/comments",
+			"PATCH /v1/posts/:id/relationships/comments",
+			"DELETE /v1/posts/:id/relationships/comments",
+		}))
+		Expect(routesOf("bananas")).To(ContainElement("POST /v1/posts/:id/relationships/bananas"))
+
+		document := api.OpenAPI()
+		Expect(document.Paths).ToNot(HaveKey("/v1/posts/{id}/comments"))
+		Expect(document.Paths["/v1/posts/{id}/relationships/comments"]).ToNot(HaveKey("post"))
+	})
+
+	Context("when renaming relationship paths", func() {
+		BeforeEach(func() {
+			api.AddResource(Post{}, source, RelationshipPath("comments", "replies"))
+		})
+
+		It("serves the relationship at the path", func() {
+			Expect(routesOf("comments")).To(ContainElement("GET /v1/posts/:id/replies"))
+
+			request("GET", "/v1/posts/1/relationships/replies", "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Body.String()).To(ContainSubstring(`"data":[{"id":"1","type":"comments"}]`))
+		})
+
+		It("uses the path in links", func() {
+			request("GET", "/v1/posts/1", "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+
+			var document struct {
+				Data struct {
+					Relationships map[string]struct {
+						Links map[string]string `json:"links"`
+					} `json:"relationships"`
+				} `json:"data"`
+			}
+			Expect(json.Unmarshal(rec.Body.Bytes(), &document)).To(Succeed())
+			Expect(document.Data.Relationships["comments"].Links).To(Equal(map[string]string{
+				"self":    "/v1/posts/1/relationships/replies",
+				"related": "/v1/posts/1/replies",
+			}))
+			Expect(document.Data.Relationships["author"].Links["related"]).To(Equal("/v1/posts/1/author"))
+		})
+	})
+})
@@ -0,0 +1,76 @@
+package api2go
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Route introspection", func() {
+	var api *API
+
+	BeforeEach(func() {
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+	})
+
+	It("lists all generated routes of a resource in order", func() {
+		api.AddResource(Post{}, &fixtureSource{posts: map[string]*Post{}})
+
+		Expect(api.Routes()).To(Equal([]Route{
+			{Method: "OPTIONS", Path: "/v1/posts", Resource: "posts", Kind: RouteOptions},
+			{Method: "GET", Path: "/v1/posts", Resource: "posts", Kind: RouteIndex},
+			{Method: "OPTIONS", Path: "/v1/posts/:id", Resource: "posts", Kind: RouteOptions},
+			{Method: "GET", Path: "/v1/posts/:id", Resource: "posts", Kind: RouteRead},
+			{Method: "GET", Path: "/v1/posts/:id/relationships/author", Resource: "posts", Relationship: "author", Kind: RouteRelationship},
+			{Method: "GET", Path: "/v1/posts/:id/author", Resource: "posts", Relationship: "author", Kind: RouteRelated},
+			{Method: "PATCH", Path: "/v1/posts/:id/relationships/author", Resource: "posts", Relationship: "author", Kind: RouteRelationship},
+			{Method: "GET", Path: "/v1/posts/:id/relationships/comments", Resource: "posts", Relationship: "comments", Kind: RouteRelationship},
+			{Method: "GET", Path: "/v1/posts/:id/comments", Resource: "posts", Relationship: "comments", Kind: RouteRelated},
+			{Method: "PATCH", Path: "/v1/posts/:id/relationships/comments", Resource: "posts", Relationship: "comments", Kind: RouteRelationship},
+			{Method: "POST", Path: "/v1/posts/:id/relationships/comments", Resource: "posts", Relationship: "comments", Kind: RouteRelationship},
+			{Method: "DELETE", Path: "/v1/posts/:id/relationships/comments", Resource: "posts", Relationship: "comments", Kind: RouteRelationship},
+			{Method: "GET", Path: "/v1/posts/:id/relationships/bananas", Resource: "posts", Relationship: "bananas", Kind: RouteRelationship},
+			{Method: "GET", Path: "/v1/posts/:id/bananas", Resource: "posts", Relationship: "bananas", Kind: RouteRelated},
+			{Method: "PATCH", Path: "/v1/posts/:id/relationships/bananas", Resource: "posts", Relationship: "bananas", Kind: RouteRelationship},
+			{Method: "POST", Path: "/v1/posts/:id/relationships/bananas", Resource: "posts", Relationship: "bananas", Kind: RouteRelationship},
+			{Method: "DELETE", Path: "/v1/posts/:id/relationships/bananas", Resource: "posts", Relationship: "bananas", Kind: RouteRelationship},
+			{Method: "POST", Path: "/v1/posts", Resource: "posts", Kind: RouteCreate},
+			{Method: "DELETE", Path: "/v1/posts/:id", Resource: "posts", Kind: RouteDelete},
+			{Method: "PATCH", Path: "/v1/posts/:id", Resource: "posts", Kind: RouteUpdate},
+		}))
+	})
+
+	It("lists routes that do not belong to a resource", func() {
+		api.EnableAtomicOperations()
+		api.ServeOpenAPI("/openapi.json")
+
+		Expect(api.Routes()).To(Equal([]Route{
+			{Method: "POST", Path: "/v1/operations", Kind: RouteOperations},
+			{Method: "GET", Path: "/v1/openapi.json", Kind: RouteOpenAPI},
+		}))
+	})
+
+	It("only lists the routes of the api", func() {
+		api.AddResource(User{}, &userSource{})
+		version := api.NewAPIVersion("v2")
+
+		Expect(version.Routes()).To(BeEmpty())
+		Expect(api.Routes()).ToNot(BeEmpty())
+	})
+
+	It("returns a copy of the routes", func() {
+		api.AddResource(User{}, &userSource{})
+		routes := api.Routes()
+		routes[0].Path = "/changed"
+
+		Expect(api.Routes()[0].Path).To(Equal("/v1/users"))
+	})
+
+	It("formats routes for logs", func() {
+		Expect(Route{Method: http.MethodGet, Path: "/v1/posts/:id/comments", Resource: "posts", Relationship: "comments", Kind: RouteRelated}.String()).
+			To(Equal("GET /v1/posts/:id/comments (posts comments related)"))
+		Expect(Route{Method: http.MethodPost, Path: "/v1/operations", Kind: RouteOperations}.String()).
+			To(Equal("POST /v1/operations (operations)"))
+	})
+})
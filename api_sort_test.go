@@ -0,0 +1,103 @@
+package api2go
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type sortableCommentSource struct {
+	commentSource
+	lastRequest *Request
+}
+
+func (s *sortableCommentSource) FindAll(req Request) (Responder, error) {
+	s.lastRequest = &req
+	return &Response{Res: []Comment{}}, nil
+}
+
+func (s *sortableCommentSource) SortableFields() []string {
+	return []string{"value", "created"}
+}
+
+var _ = Describe("Sort query parameter", func() {
+	var (
+		api    *API
+		rec    *httptest.ResponseRecorder
+		source *sortableCommentSource
+	)
+
+	BeforeEach(func() {
+		source = &sortableCommentSource{}
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		api.AddResource(Post{}, &fixtureSource{map[string]*Post{"1": {ID: "1"}}, false})
+		api.AddResource(Comment{}, source)
+		rec = httptest.NewRecorder()
+	})
+
+	It("parses the sort fields", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?sort=-created,value,,", nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parseSortFields(req)).To(Equal([]SortField{
+			{Name: "created", Descending: true},
+			{Name: "value"},
+		}))
+	})
+
+	It("has no sort fields without sort parameter", func() {
+		req, err := http.NewRequest("GET", "/v1/comments", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(source.lastRequest.Sort).To(BeNil())
+	})
+
+	It("passes the sort fields to FindAll", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?sort=-created,value", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(source.lastRequest.Sort).To(Equal([]SortField{
+			{Name: "created", Descending: true},
+			{Name: "value"},
+		}))
+	})
+
+	It("passes the sort fields to FindAll of related collections", func() {
+		req, err := http.NewRequest("GET", "/v1/posts/1/comments?sort=-value", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(source.lastRequest.QueryParams["postsID"]).To(Equal([]string{"1"}))
+		Expect(source.lastRequest.Sort).To(Equal([]SortField{{Name: "value", Descending: true}}))
+	})
+
+	It("rejects unknown sort fields", func() {
+		req, err := http.NewRequest("GET", "/v1/comments?sort=-value,title", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(source.lastRequest).To(BeNil())
+
+		var document HTTPError
+		Expect(json.Unmarshal(rec.Body.Bytes(), &document)).To(Succeed())
+		Expect(document.Errors).To(Equal([]Error{{
+			Status: "400",
+			Code:   codeInvalidQuerySort,
+			Title:  `Sorting by "title" is not supported for type "comments"`,
+			Detail: "Please make sure you do only sort by supported fields",
+			Source: &ErrorSource{Parameter: "sort"},
+		}}))
+	})
+
+	It("rejects unknown sort fields of related collections", func() {
+		req, err := http.NewRequest("GET", "/v1/posts/1/comments?sort=title", nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(source.lastRequest).To(BeNil())
+	})
+})
@@ -0,0 +1,201 @@
+package api2go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manyminds/api2go/jsonapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type streamingUserSource struct {
+	userSource
+	count  int
+	failAt int
+}
+
+func (s *streamingUserSource) FindAll(req Request) (Responder, error) {
+	return StreamResponse{
+		Response: Response{Meta: map[string]interface{}{"exported": true}},
+		Items: func(yield func(jsonapi.MarshalIdentifier, error) bool) {
+			for i := 1; i <= s.count; i++ {
+				if i == s.failAt {
+					yield(nil, errors.New("database is gone"))
+					return
+				}
+
+				if !yield(User{ID: strconv.Itoa(i), Name: "User " + strconv.Itoa(i)}, nil) {
+					return
+				}
+			}
+		},
+	}, nil
+}
+
+var _ = Describe("Streaming responses", func() {
+	var (
+		api    *API
+		rec    *httptest.ResponseRecorder
+		source *streamingUserSource
+		logged []error
+	)
+
+	BeforeEach(func() {
+		source = &streamingUserSource{count: 3}
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		api.AddResource(User{}, source)
+		logged = nil
+		api.SetErrorLogger(func(r *http.Request, err error) {
+			logged = append(logged, err)
+		})
+		rec = httptest.NewRecorder()
+	})
+
+	request := func(url string) {
+		req, err := http.NewRequest("GET", url, nil)
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+	}
+
+	It("writes all items of the stream", func() {
+		request("/v1/users")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal(defaultContentTypHeader))
+		Expect(rec.Body.String()).To(MatchJSON(`{
+			"data": [
+				{"type": "users", "id": "1", "attributes": {"name": "User 1", "info": ""}},
+				{"type": "users", "id": "2", "attributes": {"name": "User 2", "info": ""}},
+				{"type": "users", "id": "3", "attributes": {"name": "User 3", "info": ""}}
+			],
+			"meta": {"exported": true}
+		}`))
+	})
+
+	It("writes an empty stream", func() {
+		source.count = 0
+		request("/v1/users")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(MatchJSON(`{"data": [], "meta": {"exported": true}}`))
+	})
+
+	It("applies sparse fieldsets", func() {
+		request("/v1/users?fields[users]=name")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).ToNot(ContainSubstring(`"info"`))
+	})
+
+	It("returns errors before the first item as error document", func() {
+		request("/v1/users?fields[users]=age")
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring(`Field \"age\" does not exist for type \"users\"`))
+
+		source.failAt = 1
+		rec = httptest.NewRecorder()
+		request("/v1/users")
+		Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		Expect(rec.Body.String()).To(MatchJSON(`{"errors":[{"status":"500","title":"database is gone"}]}`))
+	})
+
+	It("logs later errors and ends responses that can not be aborted", func() {
+		source.failAt = 3
+		request("/v1/users")
+
+		Expect(logged).To(HaveLen(1))
+		Expect(logged[0]).To(MatchError("database is gone"))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(json.Valid(rec.Body.Bytes())).To(BeFalse())
+	})
+
+	It("aborts responses that can not be hijacked with http.ErrAbortHandler under net/http", func() {
+		// the default router, others like gin recover from panics themselves
+		api = NewAPI(testPrefix)
+		api.AddResource(User{}, source)
+		api.SetErrorLogger(func(r *http.Request, err error) {
+			logged = append(logged, err)
+		})
+
+		source.failAt = 3
+		req, err := http.NewRequest("GET", "/v1/users", nil)
+		Expect(err).ToNot(HaveOccurred())
+		req = req.WithContext(context.WithValue(req.Context(), http.ServerContextKey, &http.Server{}))
+
+		Expect(func() { api.Handler().ServeHTTP(rec, req) }).To(PanicWith(http.ErrAbortHandler))
+		Expect(logged).To(HaveLen(1))
+	})
+
+	It("sends the cache headers only with the first item", func() {
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		api.AddResource(User{}, source).SetCachePolicy(CachePolicy{MaxAge: time.Hour})
+
+		source.failAt = 1
+		request("/v1/users")
+		Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		Expect(rec.Header().Get("Cache-Control")).To(BeEmpty())
+
+		source.failAt = 0
+		rec = httptest.NewRecorder()
+		request("/v1/users")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Cache-Control")).To(Equal("public, max-age=3600"))
+	})
+
+	It("sends a truncated body to clients for later errors", func() {
+		source.count = 1000
+		source.failAt = 1000
+		server := httptest.NewServer(api.Handler())
+		defer server.Close()
+
+		response, err := http.Get(server.URL + "/v1/users")
+		if err != nil {
+			// routers that buffer the whole response are aborted before the header is sent
+			Expect(err).To(MatchError(ContainSubstring("EOF")))
+			return
+		}
+		defer response.Body.Close()
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+
+		// routers that buffer the whole response send the truncated document completely
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+		}
+		Expect(string(body)).To(HavePrefix(`{"data":[`))
+		Expect(json.Valid(body)).To(BeFalse())
+	})
+
+	It("streams sequences and channels", func() {
+		users := func(yield func(User) bool) {
+			for _, name := range []string{"a", "b"} {
+				if !yield(User{ID: name}) {
+					return
+				}
+			}
+		}
+
+		var ids []string
+		for item, err := range StreamSeq(iter.Seq[User](users)) {
+			Expect(err).ToNot(HaveOccurred())
+			ids = append(ids, item.GetID().ID)
+		}
+
+		channel := make(chan User, 1)
+		go func() {
+			channel <- User{ID: "c"}
+			close(channel)
+		}()
+		for item := range StreamChannel(channel) {
+			ids = append(ids, item.GetID().ID)
+		}
+
+		Expect(strings.Join(ids, ",")).To(Equal("a,b,c"))
+	})
+})
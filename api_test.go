@@ -832,7 +832,6 @@ var _ = Describe("RestHandler", func() {
 			Expect(strings.Split(rec.Header().Get("Allow"), ",")).To(Equal([]string{
 				"OPTIONS",
 				"GET",
-				"PATCH",
 				"POST",
 			}))
 		})
@@ -863,8 +862,8 @@ var _ = Describe("RestHandler", func() {
 			req, err := http.NewRequest("PATCH", "/v1/posts/1", reqBody)
 			Expect(err).To(BeNil())
 			api.Handler().ServeHTTP(rec, req)
-			Expect(rec.Code).To(Equal(http.StatusNotAcceptable))
-			Expect(rec.Body.String()).To(MatchJSON(`{"errors":[{"status":"406","title":"invalid record, no type was specified"}]}`))
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(rec.Body.String()).To(MatchJSON(`{"errors":[{"status":"400","title":"invalid record, no type was specified","source":{"pointer":"/data/type"}}]}`))
 		})
 
 		It("patch must contain type and id but does not have id", func() {
@@ -877,13 +876,13 @@ var _ = Describe("RestHandler", func() {
 			Expect(rec.Body.String()).To(MatchJSON(`{"errors":[{"status":"409","title":"id in the resource does not match servers endpoint"}]}`))
 		})
 
-		It("POST without type returns 406", func() {
+		It("POST without type returns 400", func() {
 			reqBody := strings.NewReader(`{"data": {"title": "New Title"}}`)
 			req, err := http.NewRequest("POST", "/v1/posts", reqBody)
 			Expect(err).To(BeNil())
 			api.Handler().ServeHTTP(rec, req)
-			Expect(rec.Code).To(Equal(http.StatusNotAcceptable))
-			Expect(rec.Body.String()).To(MatchJSON(`{"errors":[{"status":"406","title":"invalid record, no type was specified"}]}`))
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(rec.Body.String()).To(MatchJSON(`{"errors":[{"status":"400","title":"invalid record, no type was specified","source":{"pointer":"/data/type"}}]}`))
 
 		})
 
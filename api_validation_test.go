@@ -0,0 +1,127 @@
+package api2go
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/manyminds/api2go/jsonapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type Ticket struct {
+	ID       string `json:"-"`
+	Title    string `json:"title"`
+	Priority int    `json:"priority"`
+	Path     string `json:"file/path"`
+}
+
+func (t Ticket) GetID() jsonapi.Identifier {
+	return jsonapi.Identifier{ID: t.ID}
+}
+
+func (t *Ticket) SetID(ID jsonapi.Identifier) error {
+	t.ID = ID.ID
+	return nil
+}
+
+func (t *Ticket) Validate(req Request) []FieldError {
+	var result []FieldError
+	if t.Title == "" {
+		result = append(result, FieldError{Field: "title", Title: "must not be empty"})
+	}
+
+	if t.Priority < 1 || t.Priority > 5 {
+		result = append(result, FieldError{Field: "priority", Title: "is out of range", Detail: "priority must be between 1 and 5", Code: "PRIORITY_RANGE"})
+	}
+
+	if strings.HasPrefix(t.Path, "/") {
+		result = append(result, FieldError{Field: "file/path", Title: "must be relative"})
+	}
+
+	return result
+}
+
+type ticketSource struct {
+	tickets map[string]Ticket
+}
+
+func (s *ticketSource) FindAll(req Request) (Responder, error) {
+	return &Response{Res: []Ticket{}}, nil
+}
+
+func (s *ticketSource) FindOne(id string, req Request) (Responder, error) {
+	return &Response{Res: s.tickets[id]}, nil
+}
+
+func (s *ticketSource) Create(obj interface{}, req Request) (Responder, error) {
+	ticket := obj.(Ticket)
+	ticket.ID = "2"
+	s.tickets[ticket.ID] = ticket
+	return &Response{Res: ticket, Code: http.StatusCreated}, nil
+}
+
+func (s *ticketSource) Delete(id string, req Request) (Responder, error) {
+	delete(s.tickets, id)
+	return &Response{Code: http.StatusNoContent}, nil
+}
+
+func (s *ticketSource) Update(obj interface{}, req Request) (Responder, error) {
+	ticket := obj.(Ticket)
+	s.tickets[ticket.ID] = ticket
+	return &Response{Code: http.StatusNoContent}, nil
+}
+
+var _ = Describe("Validation", func() {
+	var (
+		api    *API
+		rec    *httptest.ResponseRecorder
+		source *ticketSource
+	)
+
+	BeforeEach(func() {
+		source = &ticketSource{tickets: map[string]Ticket{"1": {ID: "1", Title: "Bug", Priority: 2}}}
+		api = NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+		api.AddResource(Ticket{}, source)
+		rec = httptest.NewRecorder()
+	})
+
+	request := func(method, url, body string) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+		api.Handler().ServeHTTP(rec, req)
+	}
+
+	It("creates valid resources", func() {
+		request("POST", "/v1/tickets", `{"data": {"type": "tickets", "attributes": {"title": "New", "priority": 3}}}`)
+		Expect(rec.Code).To(Equal(http.StatusCreated))
+		Expect(source.tickets).To(HaveKey("2"))
+	})
+
+	It("returns one error per invalid field on create", func() {
+		request("POST", "/v1/tickets", `{"data": {"type": "tickets", "attributes": {"priority": 9, "file/path": "/etc"}}}`)
+		Expect(rec.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(source.tickets).ToNot(HaveKey("2"))
+		Expect(rec.Body.String()).To(MatchJSON(`{"errors": [
+			{"status": "422", "code": "API2GO_VALIDATION_FAILED", "title": "must not be empty", "source": {"pointer": "/data/attributes/title"}},
+			{"status": "422", "code": "PRIORITY_RANGE", "title": "is out of range", "detail": "priority must be between 1 and 5", "source": {"pointer": "/data/attributes/priority"}},
+			{"status": "422", "code": "API2GO_VALIDATION_FAILED", "title": "must be relative", "source": {"pointer": "/data/attributes/file~1path"}}
+		]}`))
+	})
+
+	It("validates the updated resource", func() {
+		request("PATCH", "/v1/tickets/1", `{"data": {"type": "tickets", "id": "1", "attributes": {"priority": 0}}}`)
+		Expect(rec.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(rec.Body.String()).To(MatchJSON(`{"errors": [
+			{"status": "422", "code": "PRIORITY_RANGE", "title": "is out of range", "detail": "priority must be between 1 and 5", "source": {"pointer": "/data/attributes/priority"}}
+		]}`))
+		Expect(source.tickets["1"].Priority).To(Equal(2))
+	})
+
+	It("updates valid resources", func() {
+		request("PATCH", "/v1/tickets/1", `{"data": {"type": "tickets", "id": "1", "attributes": {"priority": 5}}}`)
+		Expect(rec.Code).To(Equal(http.StatusNoContent))
+		Expect(source.tickets["1"].Priority).To(Equal(5))
+	})
+})
@@ -0,0 +1,542 @@
+package api2go
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/manyminds/api2go/jsonapi"
+)
+
+const (
+	atomicExtension      = "https://jsonapi.org/ext/atomic"
+	atomicOperationsPath = "operations"
+
+	atomicOperationAdd    = "add"
+	atomicOperationUpdate = "update"
+	atomicOperationRemove = "remove"
+)
+
+type atomicDocument struct {
+	Operations []atomicOperation `json:"atomic:operations"`
+}
+
+type atomicOperation struct {
+	Op   string           `json:"op"`
+	Ref  *atomicReference `json:"ref,omitempty"`
+	Href string           `json:"href,omitempty"`
+	Data json.RawMessage  `json:"data,omitempty"`
+}
+
+type atomicReference struct {
+	Type         string `json:"type"`
+	ID           string `json:"id,omitempty"`
+	LID          string `json:"lid,omitempty"`
+	Relationship string `json:"relationship,omitempty"`
+}
+
+type atomicResultDocument struct {
+	Results []atomicResult `json:"atomic:results"`
+}
+
+type atomicResult struct {
+	Data *jsonapi.Data          `json:"data,omitempty"`
+	Meta map[string]interface{} `json:"meta,omitempty"`
+}
+
+// atomicBatch executes the operations of one atomic request and keeps track of
+// local ids and the transactions that have been started.
+type atomicBatch struct {
+	c     APIContexter
+	r     *http.Request
+	info  information
+	api   *API
+	lids  map[string]string
+	begun []AtomicTransactor
+	names map[string]bool
+}
+
+// addOperationsRoute registers the endpoint of the atomic operations extension
+func (api *API) addOperationsRoute() {
+	api.handle(Route{Method: http.MethodPost, Path: api.routePath(atomicOperationsPath), Kind: RouteOperations}, func(c APIContexter, w http.ResponseWriter, r *http.Request, _ map[string]string, info information) error {
+		return api.handleOperations(c, w, r, info)
+	})
+}
+
+func (api *API) handleOperations(c APIContexter, w http.ResponseWriter, r *http.Request, info information) error {
+	if !contains(getNegotiation(r).extensions, atomicExtension) {
+		return NewHTTPError(nil, fmt.Sprintf(`The Content-Type of atomic operations must be %s; ext="%s"`, api.ContentType, atomicExtension), http.StatusUnsupportedMediaType)
+	}
+
+	body, err := unmarshalRequest(r)
+	if err != nil {
+		return err
+	}
+
+	document := atomicDocument{}
+	err = json.Unmarshal(body, &document)
+	if err != nil {
+		return NewHTTPError(err, "Invalid atomic operations document", http.StatusBadRequest)
+	}
+
+	if len(document.Operations) == 0 {
+		return NewHTTPError(nil, `The document must contain a non-empty "atomic:operations" array`, http.StatusBadRequest)
+	}
+
+	batch := &atomicBatch{c: c, r: r, info: info, api: api, lids: map[string]string{}, names: map[string]bool{}}
+	results := make([]atomicResult, 0, len(document.Operations))
+	for index, operation := range document.Operations {
+		result, err := batch.execute(operation)
+		if err != nil {
+			batch.rollback(batch.begun)
+			return atomicOperationError(err, index)
+		}
+
+		results = append(results, result)
+	}
+
+	err = batch.commit()
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if result.Data != nil || len(result.Meta) > 0 {
+			data, err := json.Marshal(atomicResultDocument{Results: results})
+			if err != nil {
+				return err
+			}
+
+			writeResult(w, data, http.StatusOK, fmt.Sprintf(`%s; ext="%s"`, api.ContentType, atomicExtension))
+			return nil
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (b *atomicBatch) execute(operation atomicOperation) (atomicResult, error) {
+	if operation.Href != "" {
+		return atomicResult{}, NewHTTPError(nil, `Operations targeting a "href" are not supported, use "ref" instead`, http.StatusBadRequest)
+	}
+
+	var data interface{}
+	if len(operation.Data) > 0 {
+		// numbers are kept as json.Number, so they survive the round trip into the
+		// resource struct without losing precision
+		decoder := json.NewDecoder(bytes.NewReader(operation.Data))
+		decoder.UseNumber()
+		err := decoder.Decode(&data)
+		if err != nil {
+			return atomicResult{}, NewHTTPError(err, "Invalid data of operation", http.StatusBadRequest)
+		}
+	}
+
+	ref := operation.Ref
+	if ref != nil && ref.Relationship != "" {
+		err := b.resolveIdentifiers(data, "/data")
+		if err != nil {
+			return atomicResult{}, err
+		}
+	} else {
+		err := b.resolveLocalIDs(data)
+		if err != nil {
+			return atomicResult{}, err
+		}
+	}
+
+	if ref != nil && ref.ID == "" && ref.LID != "" {
+		id, ok := b.lids[ref.Type+":"+ref.LID]
+		if !ok {
+			return atomicResult{}, unknownLocalIDError(ref.Type, ref.LID, "/ref/lid")
+		}
+		ref.ID = id
+	}
+
+	if ref != nil && ref.Relationship != "" {
+		return atomicResult{}, b.editRelationship(operation.Op, ref, data)
+	}
+
+	switch operation.Op {
+	case atomicOperationAdd:
+		return b.add(data)
+	case atomicOperationUpdate:
+		return b.update(ref, data)
+	case atomicOperationRemove:
+		return b.remove(ref)
+	default:
+		return atomicResult{}, NewHTTPError(nil, fmt.Sprintf(`Unknown operation "%s"`, operation.Op), http.StatusBadRequest)
+	}
+}
+
+func (b *atomicBatch) add(data interface{}) (atomicResult, error) {
+	object, ok := data.(map[string]interface{})
+	if !ok {
+		return atomicResult{}, NewHTTPError(nil, "An add operation must contain a resource object", http.StatusBadRequest)
+	}
+
+	resourceType, _ := object["type"].(string)
+	res, err := b.resource(resourceType)
+	if err != nil {
+		return atomicResult{}, err
+	}
+
+	source, ok := res.source.(ResourceCreator)
+	if !ok {
+		return atomicResult{}, NewHTTPError(nil, fmt.Sprintf("Resource %s does not implement the ResourceCreator interface", res.name), http.StatusForbidden)
+	}
+
+	if err := res.checkOperation(OperationCreate, ""); err != nil {
+		return atomicResult{}, err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		return atomicResult{}, err
+	}
+
+	err = b.begin(res)
+	if err != nil {
+		return atomicResult{}, err
+	}
+
+	response, err := res.create(source, b.c, b.r, body)
+	if err != nil {
+		return atomicResult{}, err
+	}
+
+	if created, ok := response.Result().(jsonapi.MarshalIdentifier); ok {
+		if lid, _ := object["lid"].(string); lid != "" {
+			b.lids[resourceType+":"+lid] = created.GetID().ID
+		}
+	}
+
+	switch response.StatusCode() {
+	case http.StatusCreated:
+		return b.result(response)
+	case http.StatusAccepted, http.StatusNoContent:
+		return atomicResult{}, nil
+	default:
+		return atomicResult{}, fmt.Errorf("invalid status code %d from resource %s for method Create", response.StatusCode(), res.name)
+	}
+}
+
+func (b *atomicBatch) update(ref *atomicReference, data interface{}) (atomicResult, error) {
+	object, ok := data.(map[string]interface{})
+	if !ok {
+		return atomicResult{}, NewHTTPError(nil, "An update operation must contain a resource object", http.StatusBadRequest)
+	}
+
+	resourceType, _ := object["type"].(string)
+	id, _ := object["id"].(string)
+	if ref != nil {
+		resourceType = ref.Type
+		id = ref.ID
+	}
+
+	res, err := b.resource(resourceType)
+	if err != nil {
+		return atomicResult{}, err
+	}
+
+	source, ok := res.source.(ResourceUpdater)
+	if !ok {
+		return atomicResult{}, NewHTTPError(nil, fmt.Sprintf("Resource %s does not implement the ResourceUpdater interface", res.name), http.StatusForbidden)
+	}
+
+	if err := res.checkOperation(OperationUpdate, ""); err != nil {
+		return atomicResult{}, err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		return atomicResult{}, err
+	}
+
+	err = b.begin(res)
+	if err != nil {
+		return atomicResult{}, err
+	}
+
+	response, err := res.update(source, b.c, b.r, id, body)
+	if err != nil {
+		return atomicResult{}, err
+	}
+
+	switch response.StatusCode() {
+	case http.StatusOK:
+		if response.Result() == nil {
+			return atomicResult{}, nil
+		}
+		return b.result(response)
+	case http.StatusAccepted, http.StatusNoContent:
+		return atomicResult{}, nil
+	default:
+		return atomicResult{}, fmt.Errorf("invalid status code %d from resource %s for method Update", response.StatusCode(), res.name)
+	}
+}
+
+func (b *atomicBatch) remove(ref *atomicReference) (atomicResult, error) {
+	if ref == nil {
+		return atomicResult{}, NewHTTPError(nil, `A remove operation must contain a "ref"`, http.StatusBadRequest)
+	}
+
+	res, err := b.resource(ref.Type)
+	if err != nil {
+		return atomicResult{}, err
+	}
+
+	source, ok := res.source.(ResourceDeleter)
+	if !ok {
+		return atomicResult{}, NewHTTPError(nil, fmt.Sprintf("Resource %s does not implement the ResourceDeleter interface", res.name), http.StatusForbidden)
+	}
+
+	if err := res.checkOperation(OperationDelete, ""); err != nil {
+		return atomicResult{}, err
+	}
+
+	err = b.begin(res)
+	if err != nil {
+		return atomicResult{}, err
+	}
+
+	response, err := res.delete(source, b.c, b.r, ref.ID)
+	if err != nil {
+		return atomicResult{}, err
+	}
+
+	switch response.StatusCode() {
+	case http.StatusOK:
+		return atomicResult{Meta: response.Metadata()}, nil
+	case http.StatusAccepted, http.StatusNoContent:
+		return atomicResult{}, nil
+	default:
+		return atomicResult{}, fmt.Errorf("invalid status code %d from resource %s for method Delete", response.StatusCode(), res.name)
+	}
+}
+
+// editRelationship replaces (update), adds to (add) or deletes from (remove) the
+// relationship referenced by ref
+func (b *atomicBatch) editRelationship(op string, ref *atomicReference, data interface{}) error {
+	res, err := b.resource(ref.Type)
+	if err != nil {
+		return err
+	}
+
+	source, ok := res.source.(ResourceUpdater)
+	if !ok {
+		return NewHTTPError(nil, fmt.Sprintf("Resource %s does not implement the ResourceUpdater interface", res.name), http.StatusForbidden)
+	}
+
+	var relation *jsonapi.Reference
+	for _, reference := range res.references() {
+		if reference.Name == ref.Relationship {
+			relation = &reference
+			break
+		}
+	}
+	if relation == nil {
+		return NewHTTPError(nil, fmt.Sprintf("There is no relation with the name %s", ref.Relationship), http.StatusNotFound)
+	}
+
+	operation := map[string]Operation{
+		atomicOperationUpdate: OperationReplaceRelationship,
+		atomicOperationAdd:    OperationAddToRelationship,
+		atomicOperationRemove: OperationRemoveFromRelationship,
+	}[op]
+	if err := res.checkOperation(operation, relation.Name); err != nil {
+		return err
+	}
+
+	err = b.begin(res)
+	if err != nil {
+		return err
+	}
+
+	switch op {
+	case atomicOperationUpdate:
+		return res.replaceRelation(source, b.c, b.r, ref.ID, *relation, data)
+	case atomicOperationAdd:
+		return res.editToManyRelation(source, b.c, b.r, ref.ID, *relation, data, true)
+	case atomicOperationRemove:
+		return res.editToManyRelation(source, b.c, b.r, ref.ID, *relation, data, false)
+	default:
+		return NewHTTPError(nil, fmt.Sprintf(`Unknown operation "%s"`, op), http.StatusBadRequest)
+	}
+}
+
+func (b *atomicBatch) resource(name string) (*resource, error) {
+	res := b.api.resourceByName(name)
+	if res == nil {
+		return nil, NewHTTPError(
+			errors.New("Not Found"),
+			"No resource handler is registered to handle the type "+name,
+			http.StatusNotFound,
+		)
+	}
+
+	return res, nil
+}
+
+func (b *atomicBatch) result(response Responder) (atomicResult, error) {
+	document, err := jsonapi.MarshalToStruct(response.Result(), b.info)
+	if err != nil {
+		return atomicResult{}, err
+	}
+
+	result := atomicResult{Meta: response.Metadata()}
+	if document.Data != nil {
+		result.Data = document.Data.DataObject
+	}
+
+	return result, nil
+}
+
+// resolveLocalIDs sets the id of the resource object in data if its lid was assigned
+// by a previous add operation and resolves the resource identifier objects of its
+// relationships
+func (b *atomicBatch) resolveLocalIDs(data interface{}) error {
+	object, ok := data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	lid, _ := object["lid"].(string)
+	id, _ := object["id"].(string)
+	resourceType, _ := object["type"].(string)
+	if id == "" && lid != "" {
+		if resolved, ok := b.lids[resourceType+":"+lid]; ok {
+			object["id"] = resolved
+		}
+	}
+
+	relationships, _ := object["relationships"].(map[string]interface{})
+	for name, relationship := range relationships {
+		if casted, ok := relationship.(map[string]interface{}); ok {
+			err := b.resolveIdentifiers(casted["data"], "/data/relationships/"+jsonapi.EscapePointerToken(name)+"/data")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveIdentifiers sets the ids of the resource identifier objects in data, their
+// lids must have been assigned by a previous add operation. pointer locates data in
+// the operation.
+func (b *atomicBatch) resolveIdentifiers(data interface{}, pointer string) error {
+	switch value := data.(type) {
+	case []interface{}:
+		for index, entry := range value {
+			err := b.resolveIdentifiers(entry, fmt.Sprintf("%s/%d", pointer, index))
+			if err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		lid, _ := value["lid"].(string)
+		id, _ := value["id"].(string)
+		resourceType, _ := value["type"].(string)
+		if id != "" || lid == "" {
+			return nil
+		}
+
+		resolved, ok := b.lids[resourceType+":"+lid]
+		if !ok {
+			return unknownLocalIDError(resourceType, lid, pointer+"/lid")
+		}
+		value["id"] = resolved
+	}
+
+	return nil
+}
+
+func unknownLocalIDError(resourceType, lid, pointer string) HTTPError {
+	httpError := NewHTTPError(nil, fmt.Sprintf(`Unknown local id "%s" of type %s`, lid, resourceType), http.StatusBadRequest)
+	httpError.Errors = []Error{{
+		Status: strconv.Itoa(http.StatusBadRequest),
+		Title:  httpError.msg,
+		Detail: "A lid must be assigned by a previous add operation",
+		Source: &ErrorSource{Pointer: pointer},
+	}}
+
+	return httpError
+}
+
+// begin starts a transaction on the resource source if it implements
+// AtomicTransactor and it was not started before
+func (b *atomicBatch) begin(res *resource) error {
+	transactor, ok := res.source.(AtomicTransactor)
+	if !ok || b.names[res.name] {
+		return nil
+	}
+
+	err := transactor.Begin(buildRequest(b.c, b.r))
+	if err != nil {
+		return err
+	}
+
+	b.names[res.name] = true
+	b.begun = append(b.begun, transactor)
+	return nil
+}
+
+// commit commits all started transactions in order, if one of them fails it and
+// all remaining transactions are rolled back. Transactions that were committed
+// before stay committed.
+func (b *atomicBatch) commit() error {
+	for i, transactor := range b.begun {
+		err := transactor.Commit(buildRequest(b.c, b.r))
+		if err != nil {
+			b.rollback(b.begun[i:])
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *atomicBatch) rollback(transactors []AtomicTransactor) {
+	for i := len(transactors) - 1; i >= 0; i-- {
+		err := transactors[i].Rollback(buildRequest(b.c, b.r))
+		if err != nil {
+			b.api.logError(b.r, err)
+		}
+	}
+}
+
+// atomicOperationError points all errors of err to the operation at index. Errors
+// that are no HTTPErrors are returned unchanged, so the ErrorLogger and ErrorHandler
+// of the api get the original error and internal messages are not sent to clients.
+func atomicOperationError(err error, index int) error {
+	pointer := fmt.Sprintf("/atomic:operations/%d", index)
+
+	httpError, ok := AsHTTPError(err)
+	if !ok {
+		return err
+	}
+
+	errs := httpError.Errors
+	if len(errs) == 0 {
+		errs = []Error{{Title: httpError.msg, Status: strconv.Itoa(httpError.status)}}
+	}
+
+	httpError.Errors = make([]Error, 0, len(errs))
+	for _, e := range errs {
+		if e.Source == nil {
+			e.Source = &ErrorSource{Pointer: pointer}
+		} else if strings.HasPrefix(e.Source.Pointer, "/") {
+			e.Source = &ErrorSource{Pointer: pointer + e.Source.Pointer, Parameter: e.Source.Parameter}
+		}
+		httpError.Errors = append(httpError.Errors, e)
+	}
+
+	return httpError
+}
@@ -0,0 +1,116 @@
+package api2go
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CachePolicy describes the caching headers of successful reads. It can be set for
+// all responses of a resource with Resource.SetCachePolicy and be overridden for
+// single responses with the CacheHints interface.
+type CachePolicy struct {
+	// MaxAge is the time a response may be cached, it is sent in seconds
+	MaxAge time.Duration
+	// SharedMaxAge overrides MaxAge for shared caches like CDNs, it is ignored for
+	// private responses
+	SharedMaxAge time.Duration
+	// Private forbids shared caches to store the response, public is the default
+	Private bool
+	// NoStore forbids all caches to store the response, all other fields except Vary
+	// are ignored
+	NoStore bool
+	// MustRevalidate forbids caches to serve the response once it is stale
+	MustRevalidate bool
+	// Vary lists request headers that select the response in addition to Accept,
+	// which is always sent because of the content negotiation
+	Vary []string
+}
+
+// cacheControl returns the value of the Cache-Control header
+func (p CachePolicy) cacheControl() string {
+	if p.NoStore {
+		return "no-store"
+	}
+
+	directives := []string{"public"}
+	if p.Private {
+		directives[0] = "private"
+	}
+
+	directives = append(directives, "max-age="+strconv.Itoa(int(p.MaxAge.Seconds())))
+	if p.SharedMaxAge > 0 && !p.Private {
+		directives = append(directives, "s-maxage="+strconv.Itoa(int(p.SharedMaxAge.Seconds())))
+	}
+
+	if p.MustRevalidate {
+		directives = append(directives, "must-revalidate")
+	}
+
+	return strings.Join(directives, ", ")
+}
+
+// The CacheHints interface can be implemented by a Responder to override the cache
+// policy of the resource for one response. A nil policy keeps the policy of the
+// resource.
+type CacheHints interface {
+	CachePolicy() *CachePolicy
+}
+
+// setCacheHeaders sets the Cache-Control and Vary headers of reads if the response or
+// the resource has a cache policy
+func (res *resource) setCacheHeaders(w http.ResponseWriter, r *http.Request, response Responder) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return
+	}
+
+	policy := res.cachePolicy
+	if hints, ok := response.(CacheHints); ok {
+		if override := hints.CachePolicy(); override != nil {
+			policy = override
+		}
+	}
+
+	if policy == nil {
+		return
+	}
+
+	w.Header().Set("Cache-Control", policy.cacheControl())
+
+	var vary []string
+	for _, value := range w.Header().Values("Vary") {
+		for _, header := range strings.Split(value, ",") {
+			vary = append(vary, http.CanonicalHeaderKey(strings.TrimSpace(header)))
+		}
+	}
+
+	for _, header := range append([]string{"Accept"}, policy.Vary...) {
+		header = http.CanonicalHeaderKey(header)
+		if !contains(vary, header) {
+			vary = append(vary, header)
+			w.Header().Add("Vary", header)
+		}
+	}
+}
+
+// lastModified returns the latest modification time of all Versioned elements of the
+// slice obj
+func lastModified(obj interface{}) time.Time {
+	var result time.Time
+
+	value := reflect.ValueOf(obj)
+	if value.Kind() != reflect.Slice {
+		return result
+	}
+
+	for i := 0; i < value.Len(); i++ {
+		version, ok := versionOf(value.Index(i).Interface())
+		if ok && version.LastModified.After(result) {
+			result = version.LastModified
+		}
+	}
+
+	return result
+}
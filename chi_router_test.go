@@ -0,0 +1,22 @@
+//go:build chi && !gingonic && !gorillamux && !echo && !fiber
+// +build chi,!gingonic,!gorillamux,!echo,!fiber
+
+package api2go
+
+import (
+	"log"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/manyminds/api2go/routing"
+	"github.com/manyminds/api2go/routing/chirouter"
+)
+
+func newTestRouter() routing.Routeable {
+	router := chi.NewRouter()
+	router.MethodNotAllowed(notAllowedHandler{}.ServeHTTP)
+	return chirouter.New(router)
+}
+
+func init() {
+	log.Println("Testing with chi router")
+}
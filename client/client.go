@@ -0,0 +1,413 @@
+// Package client consumes the JSON:API endpoints of api2go servers with the
+// same structs that are registered at the server.
+//
+//	c := client.New("http://localhost:31415", "v0")
+//	users := c.Resource(model.User{})
+//
+//	var user model.User
+//	err := users.FindOne(ctx, "1", &user)
+//
+// All errors returned for responses with an error status code are of type
+// *ResponseError and contain the decoded error objects.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/manyminds/api2go"
+	"github.com/manyminds/api2go/jsonapi"
+)
+
+const contentType = "application/vnd.api+json"
+
+// Client performs requests against an api2go server
+type Client struct {
+	// HTTPClient is used for all requests, http.DefaultClient is used if it is nil
+	HTTPClient *http.Client
+	// Header is added to all requests, for example to authenticate them
+	Header  http.Header
+	baseURL *url.URL
+}
+
+// New returns a client for the api at the given base url and prefix, for example
+// `New("http://localhost:31415", "v0")`. It panics if baseURL can not be parsed.
+func New(baseURL, prefix string) *Client {
+	base := strings.TrimRight(baseURL, "/")
+	if prefix := strings.Trim(prefix, "/"); prefix != "" {
+		base += "/" + prefix
+	}
+
+	parsed, err := url.Parse(base)
+	if err != nil {
+		panic(err)
+	}
+
+	return &Client{baseURL: parsed, Header: http.Header{}}
+}
+
+// Resource returns the endpoints of the resource with the type of the prototype. The
+// name is derived in the same way as for api2go.API.AddResource.
+func (c *Client) Resource(prototype jsonapi.MarshalIdentifier) *Resource {
+	if name := prototype.GetID().Name; name != "" {
+		return &Resource{client: c, name: name}
+	}
+
+	resourceType := reflect.TypeOf(prototype)
+	if resourceType.Kind() == reflect.Ptr {
+		resourceType = resourceType.Elem()
+	}
+
+	return &Resource{client: c, name: jsonapi.Jsonify(jsonapi.Pluralize(resourceType.Name()))}
+}
+
+// ResponseError is returned for responses with a status code of 400 or above
+type ResponseError struct {
+	StatusCode int
+	Errors     []api2go.Error
+}
+
+// Error returns the status code and the title of the first error object
+func (e *ResponseError) Error() string {
+	msg := fmt.Sprintf("api2go client: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if len(e.Errors) > 0 {
+		if e.Errors[0].Title != "" {
+			msg += ", " + e.Errors[0].Title
+		}
+
+		if len(e.Errors) > 1 {
+			msg += fmt.Sprintf(" and %d more errors", len(e.Errors)-1)
+		}
+	}
+
+	return msg
+}
+
+// resolve returns the absolute url of a path relative to the prefix or of a link
+// returned by the server
+func (c *Client) resolve(reference string) (*url.URL, error) {
+	parsed, err := url.Parse(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.IsAbs() || strings.HasPrefix(reference, "/") {
+		return c.baseURL.ResolveReference(parsed), nil
+	}
+
+	result := *c.baseURL
+	result.Path = strings.TrimRight(result.Path, "/") + "/" + parsed.Path
+	result.RawQuery = parsed.RawQuery
+
+	return &result, nil
+}
+
+// do sends the request and returns the status code and the body of successful
+// responses
+func (c *Client) do(ctx context.Context, method string, target *url.URL, body interface{}) (int, []byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), reader)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for key, values := range c.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", contentType)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		responseError := &ResponseError{StatusCode: resp.StatusCode}
+		var document struct {
+			Errors []api2go.Error `json:"errors"`
+		}
+		if json.Unmarshal(data, &document) == nil {
+			responseError.Errors = document.Errors
+		}
+
+		return resp.StatusCode, nil, responseError
+	}
+
+	return resp.StatusCode, data, nil
+}
+
+// Resource contains the endpoints of one resource type
+type Resource struct {
+	client *Client
+	name   string
+}
+
+// Name returns the name of the resource as used in urls and the type field
+func (r *Resource) Name() string {
+	return r.name
+}
+
+func (r *Resource) url(query url.Values, path ...string) (*url.URL, error) {
+	escaped := []string{url.PathEscape(r.name)}
+	for _, part := range path {
+		escaped = append(escaped, url.PathEscape(part))
+	}
+
+	target, err := r.client.resolve(strings.Join(escaped, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(query) > 0 {
+		target.RawQuery = query.Encode()
+	}
+
+	return target, nil
+}
+
+// FindOne reads the resource with the given id into target, which must be a
+// pointer to a struct that implements jsonapi.UnmarshalIdentifier
+func (r *Resource) FindOne(ctx context.Context, id string, target interface{}) error {
+	return r.get(ctx, target, id)
+}
+
+// FindAll reads all resources into target, which must be a pointer to a slice of
+// structs. The query is sent with the first request, `next` links of paginated
+// responses are followed until the last page has been read.
+func (r *Resource) FindAll(ctx context.Context, target interface{}, query url.Values) error {
+	next, err := r.url(query)
+	if err != nil {
+		return err
+	}
+
+	return r.client.findAll(ctx, next, target)
+}
+
+// FindRelated reads the related resources of the given relationship into target,
+// which must be a pointer to a struct for to-one and to a slice of structs for
+// to-many relationships.
+func (r *Resource) FindRelated(ctx context.Context, id, relation string, target interface{}) error {
+	if reflect.TypeOf(target).Kind() == reflect.Ptr && reflect.TypeOf(target).Elem().Kind() == reflect.Slice {
+		next, err := r.url(nil, id, relation)
+		if err != nil {
+			return err
+		}
+
+		return r.client.findAll(ctx, next, target)
+	}
+
+	return r.get(ctx, target, id, relation)
+}
+
+// FindRelation returns the resource identifiers of the given relationship. The
+// result is empty for empty to-one relationships.
+func (r *Resource) FindRelation(ctx context.Context, id, relation string) ([]jsonapi.Identifier, error) {
+	target, err := r.url(nil, id, "relationships", relation)
+	if err != nil {
+		return nil, err
+	}
+
+	_, data, err := r.client.do(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var document struct {
+		Data *jsonapi.RelationshipDataContainer `json:"data"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	if document.Data == nil {
+		return []jsonapi.Identifier{}, nil
+	}
+
+	if document.Data.DataObject != nil {
+		return []jsonapi.Identifier{*document.Data.DataObject}, nil
+	}
+
+	return append([]jsonapi.Identifier{}, document.Data.DataArray...), nil
+}
+
+// Create sends obj to the server. If the server responds with the created
+// resource, it is unmarshalled into obj, which must be a pointer in that case.
+func (r *Resource) Create(ctx context.Context, obj interface{}) error {
+	target, err := r.url(nil)
+	if err != nil {
+		return err
+	}
+
+	return r.send(ctx, http.MethodPost, target, obj)
+}
+
+// Update sends obj to the server. If the server responds with the updated
+// resource, it is unmarshalled into obj, which must be a pointer in that case.
+func (r *Resource) Update(ctx context.Context, obj jsonapi.MarshalIdentifier) error {
+	target, err := r.url(nil, obj.GetID().ID)
+	if err != nil {
+		return err
+	}
+
+	return r.send(ctx, http.MethodPatch, target, obj)
+}
+
+// Delete deletes the resource with the given id
+func (r *Resource) Delete(ctx context.Context, id string) error {
+	target, err := r.url(nil, id)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.client.do(ctx, http.MethodDelete, target, nil)
+	return err
+}
+
+// ReplaceToOneRelation replaces the given to-one relationship, a nil identifier
+// empties it
+func (r *Resource) ReplaceToOneRelation(ctx context.Context, id, relation string, identifier *jsonapi.Identifier) error {
+	return r.editRelation(ctx, http.MethodPatch, id, relation, identifier)
+}
+
+// ReplaceToManyRelation replaces all members of the given to-many relationship
+func (r *Resource) ReplaceToManyRelation(ctx context.Context, id, relation string, identifiers []jsonapi.Identifier) error {
+	return r.editRelation(ctx, http.MethodPatch, id, relation, append([]jsonapi.Identifier{}, identifiers...))
+}
+
+// AddToManyRelation adds members to the given to-many relationship
+func (r *Resource) AddToManyRelation(ctx context.Context, id, relation string, identifiers []jsonapi.Identifier) error {
+	return r.editRelation(ctx, http.MethodPost, id, relation, append([]jsonapi.Identifier{}, identifiers...))
+}
+
+// DeleteToManyRelation removes members from the given to-many relationship
+func (r *Resource) DeleteToManyRelation(ctx context.Context, id, relation string, identifiers []jsonapi.Identifier) error {
+	return r.editRelation(ctx, http.MethodDelete, id, relation, append([]jsonapi.Identifier{}, identifiers...))
+}
+
+func (r *Resource) editRelation(ctx context.Context, method, id, relation string, data interface{}) error {
+	target, err := r.url(nil, id, "relationships", relation)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.client.do(ctx, method, target, map[string]interface{}{"data": data})
+	return err
+}
+
+func (r *Resource) get(ctx context.Context, target interface{}, path ...string) error {
+	resourceURL, err := r.url(nil, path...)
+	if err != nil {
+		return err
+	}
+
+	_, data, err := r.client.do(ctx, http.MethodGet, resourceURL, nil)
+	if err != nil {
+		return err
+	}
+
+	return jsonapi.Unmarshal(data, target)
+}
+
+func (r *Resource) send(ctx context.Context, method string, target *url.URL, obj interface{}) error {
+	document, err := jsonapi.MarshalToStruct(obj, nil)
+	if err != nil {
+		return err
+	}
+
+	status, data, err := r.client.do(ctx, method, target, document)
+	if err != nil {
+		return err
+	}
+
+	if status == http.StatusNoContent || status == http.StatusAccepted || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	var result struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+
+	if len(result.Data) == 0 || string(result.Data) == "null" {
+		return nil
+	}
+
+	return jsonapi.Unmarshal(data, obj)
+}
+
+// findAll reads all pages starting at next into target
+func (c *Client) findAll(ctx context.Context, next *url.URL, target interface{}) error {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.Elem().Kind() != reflect.Slice {
+		return errors.New("target must be a pointer to a slice")
+	}
+
+	sliceType := targetValue.Elem().Type()
+	result := reflect.MakeSlice(sliceType, 0, 0)
+	visited := map[string]bool{}
+
+	for next != nil && !visited[next.String()] {
+		visited[next.String()] = true
+
+		_, data, err := c.do(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return err
+		}
+
+		page := reflect.New(sliceType)
+		if err := jsonapi.Unmarshal(data, page.Interface()); err != nil {
+			return err
+		}
+		result = reflect.AppendSlice(result, page.Elem())
+
+		var document struct {
+			Links jsonapi.Links `json:"links"`
+		}
+		if err := json.Unmarshal(data, &document); err != nil {
+			return err
+		}
+
+		next = nil
+		if link, ok := document.Links["next"]; ok && link.Href != "" {
+			next, err = c.resolve(link.Href)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	targetValue.Elem().Set(result)
+	return nil
+}
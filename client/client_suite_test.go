@@ -0,0 +1,17 @@
+package client_test
+
+import (
+	"io"
+	"log"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	log.SetOutput(io.Discard)
+	RunSpecs(t, "Client Suite")
+}
@@ -0,0 +1,150 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/manyminds/api2go"
+	"github.com/manyminds/api2go/client"
+	"github.com/manyminds/api2go/examples/model"
+	"github.com/manyminds/api2go/examples/resource"
+	"github.com/manyminds/api2go/examples/storage"
+	"github.com/manyminds/api2go/jsonapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		server     *httptest.Server
+		users      *client.Resource
+		chocolates *client.Resource
+		ctx        context.Context
+	)
+
+	BeforeEach(func() {
+		api := api2go.NewAPI("v0")
+		userStorage := storage.NewUserStorage()
+		chocStorage := storage.NewChocolateStorage()
+		api.AddResource(model.User{}, resource.UserResource{ChocStorage: chocStorage, UserStorage: userStorage})
+		api.AddResource(model.Chocolate{}, resource.ChocolateResource{ChocStorage: chocStorage, UserStorage: userStorage})
+		server = httptest.NewServer(api.Handler())
+
+		c := client.New(server.URL, "v0")
+		users = c.Resource(model.User{})
+		chocolates = c.Resource(&model.Chocolate{})
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	createUser := func(name string, sweets ...string) *model.User {
+		user := &model.User{Username: name, ChocolatesIDs: sweets}
+		Expect(users.Create(ctx, user)).To(Succeed())
+		Expect(user.ID).ToNot(BeEmpty())
+		return user
+	}
+
+	createChocolate := func(name string) *model.Chocolate {
+		chocolate := &model.Chocolate{Name: name, Taste: "Very Good"}
+		Expect(chocolates.Create(ctx, chocolate)).To(Succeed())
+		Expect(chocolate.ID).ToNot(BeEmpty())
+		return chocolate
+	}
+
+	It("derives the resource names like the server", func() {
+		Expect(users.Name()).To(Equal("users"))
+		Expect(chocolates.Name()).To(Equal("chocolates"))
+	})
+
+	It("creates and reads resources", func() {
+		created := createUser("marvin")
+
+		var user model.User
+		Expect(users.FindOne(ctx, created.ID, &user)).To(Succeed())
+		Expect(user.ID).To(Equal(created.ID))
+		Expect(user.Username).To(Equal("marvin"))
+	})
+
+	It("updates and deletes resources", func() {
+		user := createUser("marvin")
+		user.Username = "better marvin"
+		Expect(users.Update(ctx, user)).To(Succeed())
+
+		var updated model.User
+		Expect(users.FindOne(ctx, user.ID, &updated)).To(Succeed())
+		Expect(updated.Username).To(Equal("better marvin"))
+
+		Expect(users.Delete(ctx, user.ID)).To(Succeed())
+		err := users.FindOne(ctx, user.ID, &updated)
+		var responseError *client.ResponseError
+		Expect(errors.As(err, &responseError)).To(BeTrue())
+		Expect(responseError.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("follows pagination links", func() {
+		for _, name := range []string{"a", "b", "c", "d", "e"} {
+			createUser(name)
+		}
+
+		var result []model.User
+		Expect(users.FindAll(ctx, &result, url.Values{"page[number]": {"1"}, "page[size]": {"2"}})).To(Succeed())
+		names := []string{}
+		for _, user := range result {
+			names = append(names, user.Username)
+		}
+		Expect(names).To(Equal([]string{"a", "b", "c", "d", "e"}))
+	})
+
+	It("reads all resources without pagination", func() {
+		createChocolate("Ritter Sport")
+		createChocolate("Milka")
+
+		var result []model.Chocolate
+		Expect(chocolates.FindAll(ctx, &result, nil)).To(Succeed())
+		Expect(result).To(HaveLen(2))
+	})
+
+	It("reads and edits relationships", func() {
+		first := createChocolate("Ritter Sport")
+		second := createChocolate("Milka")
+		user := createUser("marvin", first.ID)
+
+		identifiers, err := users.FindRelation(ctx, user.ID, "sweets")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(identifiers).To(Equal([]jsonapi.Identifier{{ID: first.ID, Name: "chocolates"}}))
+
+		Expect(users.AddToManyRelation(ctx, user.ID, "sweets", []jsonapi.Identifier{{ID: second.ID, Name: "chocolates"}})).To(Succeed())
+		var sweets []model.Chocolate
+		Expect(users.FindRelated(ctx, user.ID, "sweets", &sweets)).To(Succeed())
+		Expect(sweets).To(HaveLen(2))
+
+		Expect(users.DeleteToManyRelation(ctx, user.ID, "sweets", []jsonapi.Identifier{{ID: first.ID, Name: "chocolates"}})).To(Succeed())
+		identifiers, err = users.FindRelation(ctx, user.ID, "sweets")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(identifiers).To(Equal([]jsonapi.Identifier{{ID: second.ID, Name: "chocolates"}}))
+
+		Expect(users.ReplaceToManyRelation(ctx, user.ID, "sweets", nil)).To(Succeed())
+		identifiers, err = users.FindRelation(ctx, user.ID, "sweets")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(identifiers).To(BeEmpty())
+	})
+
+	It("decodes error documents", func() {
+		var user model.User
+		err := users.FindOne(ctx, "404", &user)
+		Expect(err).To(HaveOccurred())
+
+		responseError, ok := err.(*client.ResponseError)
+		Expect(ok).To(BeTrue())
+		Expect(responseError.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(responseError.Errors).To(HaveLen(1))
+		Expect(responseError.Errors[0].Status).To(Equal("404"))
+		Expect(responseError.Error()).To(ContainSubstring("404 Not Found"))
+	})
+})
@@ -0,0 +1,268 @@
+package api2go
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Encoder compresses response bodies with one content coding. Encoders for codings
+// like zstd or br can be passed to API.EnableCompression in addition to GzipEncoder.
+type Encoder interface {
+	// Encoding returns the content coding as used in the Accept-Encoding and
+	// Content-Encoding headers, for example "gzip"
+	Encoding() string
+	// NewWriter returns a writer that compresses into w. It is closed after the
+	// response has been written.
+	NewWriter(w io.Writer) io.WriteCloser
+}
+
+// GzipEncoder compresses responses with gzip
+type GzipEncoder struct {
+	// Level is a compression level of compress/gzip, gzip.DefaultCompression is used
+	// for 0 and invalid levels
+	Level int
+}
+
+// Encoding returns "gzip"
+func (e GzipEncoder) Encoding() string {
+	return "gzip"
+}
+
+// NewWriter returns a gzip writer with the level of the encoder
+func (e GzipEncoder) NewWriter(w io.Writer) io.WriteCloser {
+	writer, err := gzip.NewWriterLevel(w, e.Level)
+	if err != nil || e.Level == 0 {
+		return gzip.NewWriter(w)
+	}
+
+	return writer
+}
+
+type compression struct {
+	minSize  int
+	encoders []Encoder
+}
+
+// negotiateEncoder returns the encoder with the highest quality in the Accept-Encoding
+// header of r. Encoders that have been registered first win on equal quality.
+func (c *compression) negotiateEncoder(r *http.Request) Encoder {
+	qualities := map[string]float64{}
+	for _, entry := range strings.Split(strings.Join(r.Header.Values("Accept-Encoding"), ","), ",") {
+		parts := strings.Split(entry, ";")
+		coding := strings.ToLower(strings.TrimSpace(parts[0]))
+		if coding == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range parts[1:] {
+			key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if found && strings.EqualFold(key, mediaTypeParamQuality) {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		qualities[coding] = quality
+	}
+
+	var (
+		result Encoder
+		best   float64
+	)
+	for _, encoder := range c.encoders {
+		quality, ok := qualities[strings.ToLower(encoder.Encoding())]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+
+		if ok && quality > best {
+			result, best = encoder, quality
+		}
+	}
+
+	return result
+}
+
+// compressWriter buffers the response until it has reached the minimum size and
+// compresses it from then on. Smaller responses are written uncompressed.
+type compressWriter struct {
+	http.ResponseWriter
+	encoder Encoder
+	minSize int
+	status  int
+	buffer  []byte
+	writer  io.WriteCloser
+	decided bool
+}
+
+// wrap returns a writer that compresses the response to r if the client accepts one of
+// the encodings, and a function that must be called once the response is complete
+func (c *compression) wrap(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, func()) {
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	encoder := c.negotiateEncoder(r)
+	if encoder == nil {
+		return w, func() {}
+	}
+
+	writer := &compressWriter{ResponseWriter: w, encoder: encoder, minSize: c.minSize}
+	return writer, writer.finish
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	if w.status != 0 {
+		return
+	}
+
+	w.status = status
+	if status == http.StatusNotModified {
+		// a 304 stands for the compressed representation the client would get and has to
+		// repeat its validator
+		w.encodeETag()
+	}
+
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		_ = w.start(false)
+	}
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	if !w.decided {
+		w.buffer = append(w.buffer, data...)
+		if len(w.buffer) >= w.minSize {
+			if err := w.start(true); err != nil {
+				return 0, err
+			}
+		}
+
+		return len(data), nil
+	}
+
+	if w.writer != nil {
+		return w.writer.Write(data)
+	}
+
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush compresses the response, because responses that are flushed are expected
+// to be streamed
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		_ = w.start(true)
+	}
+
+	if flusher, ok := w.writer.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the original writer for http.ResponseController
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// start writes the header and the buffered data, compressed if compress is set and the
+// response has not been encoded already
+func (w *compressWriter) start(compress bool) error {
+	w.decided = true
+
+	header := w.ResponseWriter.Header()
+	if compress && header.Get("Content-Encoding") == "" {
+		header.Set("Content-Encoding", w.encoder.Encoding())
+		header.Del("Content-Length")
+		w.encodeETag()
+		w.writer = w.encoder.NewWriter(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buffer) == 0 {
+		return nil
+	}
+
+	buffer := w.buffer
+	w.buffer = nil
+	if w.writer != nil {
+		_, err := w.writer.Write(buffer)
+		return err
+	}
+
+	_, err := w.ResponseWriter.Write(buffer)
+	return err
+}
+
+// encodeETag appends the content coding of the encoder to the ETag of the response
+func (w *compressWriter) encodeETag() {
+	header := w.ResponseWriter.Header()
+	if etag := header.Get("ETag"); etag != "" {
+		header.Set("ETag", encodeETag(etag, w.encoder.Encoding()))
+	}
+}
+
+// finish writes small responses uncompressed and closes the compressing writer
+func (w *compressWriter) finish() {
+	if !w.decided {
+		if w.status == 0 {
+			return
+		}
+		_ = w.start(false)
+	}
+
+	if w.writer != nil {
+		_ = w.writer.Close()
+	}
+}
+
+// encodeETag appends the content coding to a strong entity tag, because the compressed
+// representation is not byte for byte the same as the uncompressed one. Weak entity tags
+// are kept.
+func encodeETag(etag, encoding string) string {
+	if len(etag) < 2 || !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+
+	return strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+}
+
+// decodeETags removes the content codings that encodeETag appended from the entity tags
+// in header, so that preconditions compare the version of the resource
+func (c *compression) decodeETags(header string) string {
+	if c == nil || header == "" {
+		return header
+	}
+
+	candidates := strings.Split(header, ",")
+	for i, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		for _, encoder := range c.encoders {
+			suffix := "-" + encoder.Encoding() + `"`
+			if strings.HasPrefix(candidate, `"`) && strings.HasSuffix(candidate, suffix) && len(candidate) > len(suffix) {
+				candidate = strings.TrimSuffix(candidate, suffix) + `"`
+				break
+			}
+		}
+		candidates[i] = candidate
+	}
+
+	return strings.Join(candidates, ", ")
+}
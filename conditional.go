@@ -0,0 +1,178 @@
+package api2go
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Version identifies the state of a resource for conditional requests
+type Version struct {
+	// ETag is the entity tag of the resource, for example a revision number or a hash of
+	// its attributes. It is quoted if necessary, weak tags can be given as `W/"tag"`.
+	ETag string
+	// LastModified is the time of the last change, it is sent with a precision of seconds
+	LastModified time.Time
+}
+
+// The Versioned interface can be implemented by resource structs to support
+// conditional requests. Reads of a single resource send its `ETag` and
+// `Last-Modified` headers and are answered with 304 Not Modified if the client
+// already has the current version according to `If-None-Match` or
+// `If-Modified-Since`. Updates, deletes and writes to relationships are rejected with
+// 412 Precondition Failed if `If-Match` or `If-Unmodified-Since` do not match the
+// current version, which gives optimistic concurrency control. The current version is
+// loaded with FindOne, deletes with preconditions are rejected with 412 if the source
+// does not implement ResourceGetter.
+type Versioned interface {
+	Version() Version
+}
+
+// versionOf returns the version of obj if it or a pointer to it implements Versioned
+func versionOf(obj interface{}) (Version, bool) {
+	if versioned, ok := obj.(Versioned); ok {
+		return versioned.Version(), true
+	}
+
+	if obj != nil && reflect.TypeOf(obj).Kind() != reflect.Ptr {
+		if versioned, ok := copyObject(obj).(Versioned); ok {
+			return versioned.Version(), true
+		}
+	}
+
+	return Version{}, false
+}
+
+// isVersioned returns true if the resource struct implements Versioned
+func (res *resource) isVersioned() bool {
+	versioned := reflect.TypeOf((*Versioned)(nil)).Elem()
+	return res.resourceType.Implements(versioned) || reflect.PointerTo(res.resourceType).Implements(versioned)
+}
+
+// setVersionHeaders sets the ETag and Last-Modified headers for obj. Collections only
+// get the latest Last-Modified of their elements.
+func setVersionHeaders(w http.ResponseWriter, obj interface{}) {
+	if modified := lastModified(obj); !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+		return
+	}
+
+	version, ok := versionOf(obj)
+	if !ok {
+		return
+	}
+
+	if version.ETag != "" {
+		w.Header().Set("ETag", formatETag(version.ETag))
+	}
+
+	if !version.LastModified.IsZero() {
+		w.Header().Set("Last-Modified", version.LastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified returns true if the client already has the version of obj
+func (res *resource) notModified(r *http.Request, obj interface{}) bool {
+	version, ok := versionOf(obj)
+	if !ok {
+		return false
+	}
+
+	if header := res.api.compression.decodeETags(r.Header.Get("If-None-Match")); header != "" {
+		return matchETag(header, formatETag(version.ETag), false)
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || version.LastModified.IsZero() {
+		return false
+	}
+
+	return !version.LastModified.Truncate(time.Second).After(since)
+}
+
+// hasPreconditions returns true if the request has an If-Match or If-Unmodified-Since
+// header that applies to the resource. The headers of an atomic operations request do
+// not apply to its operations.
+func (res *resource) hasPreconditions(r *http.Request) bool {
+	if r.Header.Get("If-Match") == "" && r.Header.Get("If-Unmodified-Since") == "" {
+		return false
+	}
+
+	if contains(getNegotiation(r).extensions, atomicExtension) {
+		return false
+	}
+
+	return res.isVersioned()
+}
+
+// checkPreconditions compares the version of obj, the resource as loaded by the update
+// or delete, with the If-Match and If-Unmodified-Since headers of the request. It does
+// nothing if the request has no preconditions for the resource.
+func (res *resource) checkPreconditions(r *http.Request, obj interface{}) error {
+	if !res.hasPreconditions(r) {
+		return nil
+	}
+
+	version, _ := versionOf(obj)
+	if ifMatch := res.api.compression.decodeETags(r.Header.Get("If-Match")); ifMatch != "" {
+		if !matchETag(ifMatch, formatETag(version.ETag), true) {
+			return preconditionFailed()
+		}
+
+		return nil
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Unmodified-Since"))
+	if err != nil || version.LastModified.IsZero() {
+		return nil
+	}
+
+	if version.LastModified.Truncate(time.Second).After(since) {
+		return preconditionFailed()
+	}
+
+	return nil
+}
+
+func preconditionFailed() HTTPError {
+	return NewHTTPError(nil, "The resource has been modified", http.StatusPreconditionFailed)
+}
+
+// formatETag quotes tag unless it already is a quoted or weak entity tag, an empty tag
+// stays empty
+func formatETag(tag string) string {
+	if tag == "" || strings.HasPrefix(tag, `"`) || strings.HasPrefix(tag, `W/"`) {
+		return tag
+	}
+
+	return `"` + tag + `"`
+}
+
+// matchETag returns true if the list of entity tags in header contains etag or is
+// `*`. Weak tags never match in a strong comparison, an empty etag only matches `*`.
+func matchETag(header, etag string, strong bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+
+		if etag == "" {
+			continue
+		}
+
+		if strong {
+			if candidate == etag && !strings.HasPrefix(etag, "W/") {
+				return true
+			}
+			continue
+		}
+
+		if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -16,9 +16,23 @@ type APIContexter interface {
 	Reset()
 }
 
-// APIContext api2go context for handlers, nil implementations related to Deadline and Done.
+// RequestContextSetter is implemented by contexts that wrap the context of the
+// incoming http.Request. SetRequestContext is called after Reset for every request
+// that is handled by a generated route.
+type RequestContextSetter interface {
+	SetRequestContext(ctx context.Context)
+}
+
+// APIContext api2go context for handlers. Deadline, Done, Err and Value are delegated
+// to the context of the http.Request it has been set up for.
 type APIContext struct {
 	keys map[string]interface{}
+	ctx  context.Context
+}
+
+// SetRequestContext wraps the context of the incoming request
+func (c *APIContext) SetRequestContext(ctx context.Context) {
+	c.ctx = ctx
 }
 
 // Set a string key value in the context
@@ -40,34 +54,53 @@ func (c *APIContext) Get(key string) (value interface{}, exists bool) {
 // Reset resets all values on Context, making it safe to reuse
 func (c *APIContext) Reset() {
 	c.keys = nil
+	c.ctx = nil
 }
 
 // Deadline implements net/context
 func (c *APIContext) Deadline() (deadline time.Time, ok bool) {
+	if c.ctx != nil {
+		return c.ctx.Deadline()
+	}
 	return
 }
 
 // Done implements net/context
 func (c *APIContext) Done() <-chan struct{} {
+	if c.ctx != nil {
+		return c.ctx.Done()
+	}
 	return nil
 }
 
 // Err implements net/context
 func (c *APIContext) Err() error {
+	if c.ctx != nil {
+		return c.ctx.Err()
+	}
 	return nil
 }
 
-// Value implements net/context
+// Value implements net/context, string keys that have been Set take precedence over
+// the values of the request context
 func (c *APIContext) Value(key interface{}) interface{} {
 	if keyAsString, ok := key.(string); ok {
-		val, _ := c.Get(keyAsString)
-		return val
+		if val, exists := c.Get(keyAsString); exists {
+			return val
+		}
+	}
+
+	if c.ctx != nil {
+		return c.ctx.Value(key)
 	}
 	return nil
 }
 
 // Compile time check
-var _ APIContexter = &APIContext{}
+var (
+	_ APIContexter         = &APIContext{}
+	_ RequestContextSetter = &APIContext{}
+)
 
 // ContextQueryParams fetches the QueryParams if Set
 func ContextQueryParams(c *APIContext) map[string][]string {
@@ -1,6 +1,9 @@
 package api2go
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -52,7 +55,7 @@ var _ = Describe("Context", func() {
 		})
 	})
 
-	Context("Not yet implemented", func() {
+	Context("Without request context", func() {
 		It("Deadline", func() {
 			deadline, ok := c.Deadline()
 			Expect(deadline).To(Equal(time.Time{}))
@@ -81,6 +84,75 @@ var _ = Describe("Context", func() {
 
 	})
 
+	Context("With request context", func() {
+		type contextKey string
+
+		var (
+			parent context.Context
+			cancel context.CancelFunc
+		)
+
+		BeforeEach(func() {
+			parent, cancel = context.WithTimeout(context.WithValue(context.Background(), contextKey("request"), "value"), time.Minute)
+			c.SetRequestContext(parent)
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		It("returns the deadline of the request context", func() {
+			expected, _ := parent.Deadline()
+			deadline, ok := c.Deadline()
+			Expect(ok).To(BeTrue())
+			Expect(deadline).To(Equal(expected))
+		})
+
+		It("is done when the request context is cancelled", func() {
+			Expect(c.Err()).To(BeNil())
+			cancel()
+			Eventually(c.Done()).Should(BeClosed())
+			Expect(c.Err()).To(Equal(context.Canceled))
+		})
+
+		It("returns values of the request context", func() {
+			Expect(c.Value(contextKey("request"))).To(Equal("value"))
+		})
+
+		It("prefers values that have been set", func() {
+			c.Set("request", "set")
+			Expect(c.Value("request")).To(Equal("set"))
+			Expect(c.Value(contextKey("request"))).To(Equal("value"))
+		})
+
+		It("removes the request context on reset", func() {
+			c.Reset()
+			Expect(c.Done()).To(BeNil())
+			Expect(c.Value(contextKey("request"))).To(BeNil())
+		})
+
+		It("wraps the context of requests to generated routes", func() {
+			var (
+				deadline time.Time
+				ok       bool
+			)
+
+			api := NewAPIWithRouting(testPrefix, NewStaticResolver(""), newTestRouter())
+			api.AddResource(Comment{}, &commentSource{})
+			api.UseMiddleware(func(c APIContexter, w http.ResponseWriter, r *http.Request) {
+				deadline, ok = c.Deadline()
+			})
+
+			req, err := http.NewRequestWithContext(parent, "GET", "/v1/comments", nil)
+			Expect(err).ToNot(HaveOccurred())
+			api.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+			expected, _ := parent.Deadline()
+			Expect(ok).To(BeTrue())
+			Expect(deadline).To(Equal(expected))
+		})
+	})
+
 	Context("ContextQueryParams", func() {
 		It("returns them if set", func() {
 			queryParams := map[string][]string{
@@ -1,5 +1,5 @@
-//go:build echo && !gingonic && !gorillamux
-// +build echo,!gingonic,!gorillamux
+//go:build echo && !gingonic && !gorillamux && !chi && !fiber
+// +build echo,!gingonic,!gorillamux,!chi,!fiber
 
 package api2go
 
@@ -9,6 +9,7 @@ import (
 
 	"github.com/labstack/echo"
 	"github.com/manyminds/api2go/routing"
+	"github.com/manyminds/api2go/routing/echorouter"
 )
 
 func customHTTPErrorHandler(err error, c echo.Context) {
@@ -23,7 +24,7 @@ func newTestRouter() routing.Routeable {
 	e := echo.New()
 	// not found handler, this needs to be fixed as well: see: https://github.com/manyminds/api2go/issues/301
 	e.HTTPErrorHandler = customHTTPErrorHandler
-	return routing.Echo(e)
+	return echorouter.New(e)
 }
 
 func init() {
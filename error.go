@@ -2,9 +2,14 @@ package api2go
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"strconv"
+	"strings"
+
+	"github.com/manyminds/api2go/jsonapi"
 )
 
 // HTTPError is used for errors
@@ -50,6 +55,39 @@ type ErrorSource struct {
 	Parameter string `json:"parameter,omitempty"`
 }
 
+// ErrorHandler converts an error that occurred while handling r into the HTTPError
+// that is sent to the client. res is the resource of the route, it is nil for routes
+// that do not belong to a resource.
+type ErrorHandler func(r *http.Request, err error, res *Resource) HTTPError
+
+// ErrorLogger logs an error that occurred while handling r
+type ErrorLogger func(r *http.Request, err error)
+
+// DefaultErrorHandler returns HTTPErrors unchanged, all other errors become a 500
+// Internal Server Error with the message of the error
+func DefaultErrorHandler(r *http.Request, err error, res *Resource) HTTPError {
+	if e, ok := AsHTTPError(err); ok {
+		return e
+	}
+
+	return NewHTTPError(err, err.Error(), http.StatusInternalServerError)
+}
+
+// withDefaultStatus returns e with status 500 Internal Server Error if it has no status,
+// e.g. because it was created as a literal
+func withDefaultStatus(e HTTPError) HTTPError {
+	if e.status == 0 {
+		e.status = http.StatusInternalServerError
+	}
+
+	return e
+}
+
+// logError logs err with the standard logger
+func logError(r *http.Request, err error) {
+	log.Println(err)
+}
+
 // marshalHTTPError marshals an internal httpError
 func marshalHTTPError(input HTTPError) string {
 	if len(input.Errors) == 0 {
@@ -66,6 +104,52 @@ func marshalHTTPError(input HTTPError) string {
 	return string(data)
 }
 
+// unmarshalError converts an error of jsonapi.Unmarshal into an HTTPError. Type
+// conflicts are answered with 409 Conflict, all other malformed documents with 400
+// Bad Request. Errors returned by the unmarshal methods of the target are passed
+// through if they already are HTTPErrors.
+func unmarshalError(err error) error {
+	if httpErr, ok := AsHTTPError(err); ok {
+		return httpErr
+	}
+
+	status := http.StatusBadRequest
+	apiError := Error{Title: err.Error()}
+
+	var (
+		syntaxErr       *jsonapi.SyntaxError
+		documentErr     *jsonapi.DocumentError
+		missingTypeErr  *jsonapi.MissingTypeError
+		typeMismatchErr *jsonapi.TypeMismatchError
+		relationshipErr *jsonapi.RelationshipError
+		attributeErr    *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &typeMismatchErr):
+		status = http.StatusConflict
+		apiError.Source = &ErrorSource{Pointer: typeMismatchErr.Pointer}
+	case errors.As(err, &missingTypeErr):
+		apiError.Source = &ErrorSource{Pointer: missingTypeErr.Pointer}
+	case errors.As(err, &relationshipErr):
+		apiError.Source = &ErrorSource{Pointer: relationshipErr.Pointer}
+	case errors.As(err, &documentErr):
+		if documentErr.Pointer != "" {
+			apiError.Source = &ErrorSource{Pointer: documentErr.Pointer}
+		}
+	case errors.As(err, &syntaxErr):
+		apiError.Detail = fmt.Sprintf("invalid JSON at offset %d", syntaxErr.Offset)
+	case errors.As(err, &attributeErr):
+		// attributes are decoded with encoding/json directly into the target
+		apiError.Source = &ErrorSource{Pointer: jsonapi.FieldPointer("/data/attributes", attributeErr.Field)}
+	}
+
+	apiError.Status = strconv.Itoa(status)
+	httpErr := NewHTTPError(err, err.Error(), status)
+	httpErr.Errors = []Error{apiError}
+
+	return httpErr
+}
+
 // NewHTTPError creates a new error with message and status code.
 // `err` will be logged (but never sent to a client), `msg` will be sent and `status` is the http status code.
 // `err` can be nil.
@@ -82,3 +166,137 @@ func (e HTTPError) Error() string {
 
 	return msg
 }
+
+// Unwrap returns the error that caused the HTTPError, so it can be inspected with
+// errors.Is and errors.As
+func (e HTTPError) Unwrap() error {
+	return e.err
+}
+
+// Status returns the http status code of the error
+func (e HTTPError) Status() int {
+	return e.status
+}
+
+// AsHTTPError finds the first HTTPError in the chain of err, see errors.As. Both
+// HTTPError values and pointers are found.
+func AsHTTPError(err error) (HTTPError, bool) {
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr, true
+	}
+
+	var httpErrPtr *HTTPError
+	if errors.As(err, &httpErrPtr) && httpErrPtr != nil {
+		return *httpErrPtr, true
+	}
+
+	return HTTPError{}, false
+}
+
+// NewBadRequestError creates a new error with status 400 Bad Request
+func NewBadRequestError(err error, msg string) HTTPError {
+	return NewHTTPError(err, msg, http.StatusBadRequest)
+}
+
+// NewUnauthorizedError creates a new error with status 401 Unauthorized
+func NewUnauthorizedError(err error, msg string) HTTPError {
+	return NewHTTPError(err, msg, http.StatusUnauthorized)
+}
+
+// NewForbiddenError creates a new error with status 403 Forbidden
+func NewForbiddenError(err error, msg string) HTTPError {
+	return NewHTTPError(err, msg, http.StatusForbidden)
+}
+
+// NewNotFoundError creates a new error with status 404 Not Found
+func NewNotFoundError(err error, msg string) HTTPError {
+	return NewHTTPError(err, msg, http.StatusNotFound)
+}
+
+// NewConflictError creates a new error with status 409 Conflict
+func NewConflictError(err error, msg string) HTTPError {
+	return NewHTTPError(err, msg, http.StatusConflict)
+}
+
+// NewUnprocessableEntityError creates a new error with status 422 Unprocessable Entity
+func NewUnprocessableEntityError(err error, msg string) HTTPError {
+	return NewHTTPError(err, msg, http.StatusUnprocessableEntity)
+}
+
+// NewInternalServerError creates a new error with status 500 Internal Server Error
+func NewInternalServerError(err error, msg string) HTTPError {
+	return NewHTTPError(err, msg, http.StatusInternalServerError)
+}
+
+// NewMultiHTTPError creates an error that contains all given error objects. The status
+// code of the response is derived from their statuses as recommended by the
+// specification: the status if all errors share it, 500 Internal Server Error if any of
+// them is a server error and 400 Bad Request otherwise.
+func NewMultiHTTPError(err error, msg string, errs ...Error) HTTPError {
+	httpErr := NewHTTPError(err, msg, aggregateStatus(errs))
+	httpErr.Errors = append([]Error{}, errs...)
+	return httpErr
+}
+
+// JoinHTTPErrors combines the error objects of all errors into one HTTPError, errors that
+// are no HTTPErrors become error objects with status 500 Internal Server Error. The
+// status code is computed like in NewMultiHTTPError. nil errors are skipped, JoinHTTPErrors
+// returns nil if all errors are nil.
+func JoinHTTPErrors(errs ...error) error {
+	var (
+		causes  []error
+		objects []Error
+		titles  []string
+	)
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		causes = append(causes, err)
+		e, ok := AsHTTPError(err)
+		if !ok {
+			e = NewInternalServerError(err, err.Error())
+		}
+		titles = append(titles, e.msg)
+
+		if len(e.Errors) == 0 {
+			objects = append(objects, Error{Title: e.msg, Status: strconv.Itoa(e.status)})
+			continue
+		}
+		objects = append(objects, e.Errors...)
+	}
+
+	if len(causes) == 0 {
+		return nil
+	}
+
+	return NewMultiHTTPError(errors.Join(causes...), strings.Join(titles, ", "), objects...)
+}
+
+// aggregateStatus returns the most generally applicable status of all error objects
+func aggregateStatus(errs []Error) int {
+	result := 0
+	for _, e := range errs {
+		status, err := strconv.Atoi(e.Status)
+		if err != nil || status < http.StatusBadRequest {
+			continue
+		}
+
+		switch {
+		case result == 0 || result == status:
+			result = status
+		case status >= http.StatusInternalServerError || result >= http.StatusInternalServerError:
+			result = http.StatusInternalServerError
+		default:
+			result = http.StatusBadRequest
+		}
+	}
+
+	if result == 0 {
+		return http.StatusInternalServerError
+	}
+
+	return result
+}
@@ -2,7 +2,9 @@ package api2go
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -96,4 +98,93 @@ var _ = Describe("Errors test", func() {
 			Expect(result).To(Equal(expected))
 		})
 	})
+
+	Context("Wrapping", func() {
+		cause := errors.New("connection refused")
+
+		It("unwraps to its cause", func() {
+			httpErr := NewInternalServerError(cause, "Internal Server Error")
+			Expect(errors.Is(httpErr, cause)).To(BeTrue())
+			Expect(httpErr.Status()).To(Equal(http.StatusInternalServerError))
+		})
+
+		It("is found in wrapped errors", func() {
+			wrapped := fmt.Errorf("loading post: %w", NewNotFoundError(cause, "post not found"))
+
+			httpErr, ok := AsHTTPError(wrapped)
+			Expect(ok).To(BeTrue())
+			Expect(httpErr.Status()).To(Equal(http.StatusNotFound))
+			Expect(errors.Is(wrapped, cause)).To(BeTrue())
+		})
+
+		It("finds pointers to HTTPErrors", func() {
+			httpErr := NewForbiddenError(nil, "forbidden")
+			httpErr, ok := AsHTTPError(fmt.Errorf("wrapped: %w", &httpErr))
+			Expect(ok).To(BeTrue())
+			Expect(httpErr.Status()).To(Equal(http.StatusForbidden))
+		})
+
+		It("does not find HTTPErrors in other errors", func() {
+			_, ok := AsHTTPError(cause)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("renders wrapped HTTPErrors with their status", func() {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/v1/posts", nil)
+			handleError(fmt.Errorf("wrapped: %w", NewConflictError(nil, "conflict")), rec, req, defaultContentTypHeader)
+			Expect(rec.Code).To(Equal(http.StatusConflict))
+			Expect(rec.Body.String()).To(MatchJSON(`{"errors":[{"status":"409","title":"conflict"}]}`))
+		})
+	})
+
+	Context("Multiple errors", func() {
+		status := func(statuses ...string) int {
+			errs := []Error{}
+			for _, s := range statuses {
+				errs = append(errs, Error{Status: s})
+			}
+			return NewMultiHTTPError(nil, "multiple errors", errs...).Status()
+		}
+
+		It("uses the status that all errors share", func() {
+			Expect(status("422", "422")).To(Equal(http.StatusUnprocessableEntity))
+		})
+
+		It("uses 400 for different client errors", func() {
+			Expect(status("404", "422", "409")).To(Equal(http.StatusBadRequest))
+		})
+
+		It("uses 500 as soon as a server error is contained", func() {
+			Expect(status("404", "503")).To(Equal(http.StatusInternalServerError))
+			Expect(status("502", "503")).To(Equal(http.StatusInternalServerError))
+		})
+
+		It("ignores missing statuses", func() {
+			Expect(status("", "403")).To(Equal(http.StatusForbidden))
+			Expect(status("")).To(Equal(http.StatusInternalServerError))
+		})
+
+		It("joins errors", func() {
+			cause := errors.New("disk full")
+			err := JoinHTTPErrors(
+				nil,
+				NewUnprocessableEntityError(nil, "title must not be empty"),
+				cause,
+			)
+
+			httpErr, ok := AsHTTPError(err)
+			Expect(ok).To(BeTrue())
+			Expect(httpErr.Status()).To(Equal(http.StatusInternalServerError))
+			Expect(errors.Is(err, cause)).To(BeTrue())
+			Expect(marshalHTTPError(httpErr)).To(MatchJSON(`{"errors":[
+				{"status":"422","title":"title must not be empty"},
+				{"status":"500","title":"disk full"}
+			]}`))
+		})
+
+		It("returns nil without errors", func() {
+			Expect(JoinHTTPErrors(nil, nil)).To(BeNil())
+		})
+	})
 })
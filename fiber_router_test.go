@@ -0,0 +1,33 @@
+//go:build fiber && !gingonic && !gorillamux && !echo && !chi
+// +build fiber,!gingonic,!gorillamux,!echo,!chi
+
+package api2go
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/manyminds/api2go/routing"
+	"github.com/manyminds/api2go/routing/fiberrouter"
+)
+
+func fiberErrorHandler(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) && fiberErr.Code == http.StatusMethodNotAllowed {
+		return adaptor.HTTPHandler(notAllowedHandler{})(c)
+	}
+
+	return fiber.DefaultErrorHandler(c, err)
+}
+
+func newTestRouter() routing.Routeable {
+	app := fiber.New(fiber.Config{ErrorHandler: fiberErrorHandler})
+	return fiberrouter.New(app)
+}
+
+func init() {
+	log.Println("Testing with fiber router")
+}
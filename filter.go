@@ -0,0 +1,310 @@
+package api2go
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const codeInvalidQueryFilter = "API2GO_INVALID_FILTER_QUERY_PARAM"
+
+// FilterOperator is the comparison operator of a Filter. It is given as the
+// second bracket of a filter query parameter, e.g. `filter[price][gte]=10`.
+type FilterOperator string
+
+// The predefined filter operators. Sources are free to support any other
+// operator as well.
+const (
+	FilterEqual              FilterOperator = "eq"
+	FilterNotEqual           FilterOperator = "ne"
+	FilterGreaterThan        FilterOperator = "gt"
+	FilterGreaterThanOrEqual FilterOperator = "gte"
+	FilterLessThan           FilterOperator = "lt"
+	FilterLessThanOrEqual    FilterOperator = "lte"
+	FilterLike               FilterOperator = "like"
+)
+
+// FilterLogic is the logical operator that combines the filters and groups of
+// a FilterGroup.
+type FilterLogic string
+
+// The logical operators of filter groups, they are given as the first bracket
+// of a filter query parameter, e.g. `filter[or][0][status]=open`. "and" and
+// "or" can therefore not be used as field names.
+const (
+	FilterAnd FilterLogic = "and"
+	FilterOr  FilterLogic = "or"
+)
+
+// Filter is one parsed `filter` query parameter. `filter[status]=open,closed`
+// results in Filter{Field: "status", Operator: FilterEqual, Values: ["open", "closed"]}.
+type Filter struct {
+	Field    string
+	Operator FilterOperator
+	Values   []string
+}
+
+// Parameter returns the query parameter name of the filter, the operator is
+// omitted for FilterEqual.
+func (f Filter) Parameter() string {
+	if f.Operator == FilterEqual {
+		return fmt.Sprintf("filter[%s]", f.Field)
+	}
+
+	return fmt.Sprintf("filter[%s][%s]", f.Field, f.Operator)
+}
+
+// FilterGroup is a node of the filter tree. Its Filters and Groups are
+// combined with its Operator. The groups of a FilterOr or FilterAnd group are
+// the FilterAnd groups of all parameters with the same index, so
+//
+//	filter[or][0][status]=open&filter[or][0][price][lt]=10&filter[or][1][status]=new
+//
+// results in FilterGroup{Operator: FilterOr, Groups: [
+//
+//	{Operator: FilterAnd, Filters: [{status eq open}, {price lt 10}]},
+//	{Operator: FilterAnd, Filters: [{status eq new}]},
+//
+// ]}. Groups can be nested, e.g. `filter[or][0][and][1][status]=open`.
+type FilterGroup struct {
+	Operator FilterLogic
+	Filters  []Filter
+	Groups   []FilterGroup
+}
+
+// The FilterableFields interface can be optionally implemented by a resource
+// source to declare which fields can be filtered with which operators. api2go
+// will answer requests with any other filter and with malformed filter query
+// parameters with a 400 Bad Request before FindAll or PaginatedFindAll is
+// called.
+type FilterableFields interface {
+	FilterableFields() map[string][]FilterOperator
+}
+
+// filterGroupKey is the operator and index of a group in a filter query parameter
+type filterGroupKey struct {
+	logic FilterLogic
+	index int
+}
+
+// isFilterParameter returns true for all query parameters of the filter family
+func isFilterParameter(key string) bool {
+	return key == "filter" || strings.HasPrefix(key, "filter[")
+}
+
+// parseFilterParameter parses a filter query parameter into the groups that
+// contain the filter and the filter itself. ok is false if the parameter is not
+// a well-formed `filter[field]`, `filter[field][operator]` or group parameter.
+func parseFilterParameter(key string, values []string) (groups []filterGroupKey, filter Filter, ok bool) {
+	var segments []string
+	rest := strings.TrimPrefix(key, "filter")
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 2 || strings.Contains(rest[1:end], "[") {
+			return nil, Filter{}, false
+		}
+
+		segments = append(segments, rest[1:end])
+		rest = rest[end+1:]
+	}
+
+	for len(segments) > 0 {
+		logic := FilterLogic(segments[0])
+		if logic != FilterAnd && logic != FilterOr {
+			break
+		}
+
+		if len(segments) < 3 {
+			return nil, Filter{}, false
+		}
+
+		index, err := strconv.Atoi(segments[1])
+		if err != nil || index < 0 {
+			return nil, Filter{}, false
+		}
+
+		groups = append(groups, filterGroupKey{logic: logic, index: index})
+		segments = segments[2:]
+	}
+
+	if len(segments) < 1 || len(segments) > 2 {
+		return nil, Filter{}, false
+	}
+
+	filter = Filter{Field: segments[0], Operator: FilterEqual, Values: []string{}}
+	if len(segments) == 2 {
+		filter.Operator = FilterOperator(segments[1])
+	}
+
+	for _, value := range values {
+		filter.Values = append(filter.Values, strings.Split(value, ",")...)
+	}
+
+	return groups, filter, true
+}
+
+// filterBuilder collects the filters and indexed groups of one FilterAnd group
+type filterBuilder struct {
+	filters []Filter
+	groups  map[FilterLogic]map[int]*filterBuilder
+}
+
+func (b *filterBuilder) add(groups []filterGroupKey, filter Filter) {
+	if len(groups) == 0 {
+		b.filters = append(b.filters, filter)
+		return
+	}
+
+	if b.groups == nil {
+		b.groups = map[FilterLogic]map[int]*filterBuilder{}
+	}
+
+	key := groups[0]
+	if b.groups[key.logic] == nil {
+		b.groups[key.logic] = map[int]*filterBuilder{}
+	}
+
+	child, ok := b.groups[key.logic][key.index]
+	if !ok {
+		child = &filterBuilder{}
+		b.groups[key.logic][key.index] = child
+	}
+
+	child.add(groups[1:], filter)
+}
+
+// build returns the FilterAnd group with the filters sorted by field and
+// operator and the groups sorted by index
+func (b *filterBuilder) build() FilterGroup {
+	result := FilterGroup{Operator: FilterAnd, Filters: b.filters}
+	sort.Slice(result.Filters, func(i, j int) bool {
+		if result.Filters[i].Field != result.Filters[j].Field {
+			return result.Filters[i].Field < result.Filters[j].Field
+		}
+		return result.Filters[i].Operator < result.Filters[j].Operator
+	})
+
+	for _, logic := range []FilterLogic{FilterAnd, FilterOr} {
+		children, ok := b.groups[logic]
+		if !ok {
+			continue
+		}
+
+		indexes := make([]int, 0, len(children))
+		for index := range children {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+
+		group := FilterGroup{Operator: logic}
+		for _, index := range indexes {
+			group.Groups = append(group.Groups, children[index].build())
+		}
+		result.Groups = append(result.Groups, group)
+	}
+
+	return result
+}
+
+// parseFilters extracts the filter tree of all well-formed filter query
+// parameters of the request. The returned FilterAnd group holds the top level
+// `filter[field]` and `filter[field][operator]` parameters and the `filter[or]`
+// and `filter[and]` groups. A missing operator defaults to FilterEqual. Filters
+// and Groups are nil if there are no such parameters, malformed parameters are
+// left out, see validateFilters.
+func parseFilters(r *http.Request) FilterGroup {
+	builder := &filterBuilder{}
+	for key, values := range r.URL.Query() {
+		if !isFilterParameter(key) {
+			continue
+		}
+
+		if groups, filter, ok := parseFilterParameter(key, values); ok {
+			builder.add(groups, filter)
+		}
+	}
+
+	return builder.build()
+}
+
+// validateFilters checks the filter query parameters against the fields and
+// operators declared by a FilterableFields source and rejects malformed
+// parameters. Sources that do not implement it accept any filter.
+func (res *resource) validateFilters(query url.Values) error {
+	source, ok := res.source.(FilterableFields)
+	if !ok {
+		return nil
+	}
+
+	allowed := source.FilterableFields()
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if isFilterParameter(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	httpError := NewHTTPError(nil, "Some requested filters were invalid", http.StatusBadRequest)
+	for _, key := range keys {
+		_, filter, ok := parseFilterParameter(key, query[key])
+		if !ok {
+			httpError.Errors = append(httpError.Errors, Error{
+				Status: strconv.Itoa(http.StatusBadRequest),
+				Code:   codeInvalidQueryFilter,
+				Title:  fmt.Sprintf(`Filter parameter "%s" is not supported for type "%s"`, key, res.name),
+				Detail: "Please use filter[field], filter[field][operator] or groups like filter[or][0][field]",
+				Source: &ErrorSource{
+					Parameter: key,
+				},
+			})
+			continue
+		}
+
+		operators, ok := allowed[filter.Field]
+		if !ok {
+			httpError.Errors = append(httpError.Errors, Error{
+				Status: strconv.Itoa(http.StatusBadRequest),
+				Code:   codeInvalidQueryFilter,
+				Title:  fmt.Sprintf(`Filtering by "%s" is not supported for type "%s"`, filter.Field, res.name),
+				Detail: "Please make sure you do only filter by supported fields",
+				Source: &ErrorSource{
+					Parameter: key,
+				},
+			})
+			continue
+		}
+
+		if !containsFilterOperator(operators, filter.Operator) {
+			httpError.Errors = append(httpError.Errors, Error{
+				Status: strconv.Itoa(http.StatusBadRequest),
+				Code:   codeInvalidQueryFilter,
+				Title:  fmt.Sprintf(`Operator "%s" is not supported for filter "%s" of type "%s"`, filter.Operator, filter.Field, res.name),
+				Detail: "Please make sure you do only use supported filter operators",
+				Source: &ErrorSource{
+					Parameter: key,
+				},
+			})
+		}
+	}
+
+	if len(httpError.Errors) > 0 {
+		return httpError
+	}
+
+	return nil
+}
+
+func containsFilterOperator(operators []FilterOperator, operator FilterOperator) bool {
+	for _, allowed := range operators {
+		if allowed == operator {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,5 +1,5 @@
-//go:build gingonic && !gorillamux && !echo
-// +build gingonic,!gorillamux,!echo
+//go:build gingonic && !gorillamux && !echo && !chi && !fiber
+// +build gingonic,!gorillamux,!echo,!chi,!fiber
 
 package api2go
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/manyminds/api2go/routing"
+	"github.com/manyminds/api2go/routing/ginrouter"
 )
 
 func newTestRouter() routing.Routeable {
@@ -19,7 +20,7 @@ func newTestRouter() routing.Routeable {
 
 	gg.NoRoute(notFound)
 
-	return routing.Gin(gg)
+	return ginrouter.New(gg)
 }
 
 func init() {
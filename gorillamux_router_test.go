@@ -1,5 +1,5 @@
-//go:build !gingonic && !echo && gorillamux
-// +build !gingonic,!echo,gorillamux
+//go:build !gingonic && !echo && gorillamux && !chi && !fiber
+// +build !gingonic,!echo,gorillamux,!chi,!fiber
 
 package api2go
 
@@ -8,12 +8,13 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/manyminds/api2go/routing"
+	"github.com/manyminds/api2go/routing/gorillarouter"
 )
 
 func newTestRouter() routing.Routeable {
 	router := mux.NewRouter()
 	router.MethodNotAllowedHandler = notAllowedHandler{}
-	return routing.Gorilla(router)
+	return gorillarouter.New(router)
 }
 
 func init() {
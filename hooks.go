@@ -0,0 +1,140 @@
+package api2go
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// runHooks calls run with the source of the resource and all hooks registered with
+// Resource.AddHooks, in this order. The first error is returned.
+func (res *resource) runHooks(run func(hook interface{}) error) error {
+	if err := run(res.source); err != nil {
+		return err
+	}
+
+	for _, hook := range res.hooks {
+		if err := run(hook); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hookResult returns the result of the response, or obj if there is none
+func hookResult(response Responder, obj interface{}) interface{} {
+	if response != nil && response.Result() != nil {
+		return response.Result()
+	}
+
+	return obj
+}
+
+// copyObject returns a pointer to a shallow copy of obj
+func copyObject(obj interface{}) interface{} {
+	value := reflect.ValueOf(obj)
+	if !value.IsValid() {
+		return nil
+	}
+
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	result := reflect.New(value.Type())
+	result.Elem().Set(value)
+
+	return result.Interface()
+}
+
+// runUpdate calls Update of the source surrounded by the update hooks. oldObj is a copy
+// of the object returned by FindOne and newObj a pointer to the changed object, the source
+// gets the struct newObj points to if byValue is set. It is used by updates of resources
+// and of their relationships.
+func (res *resource) runUpdate(source ResourceUpdater, req Request, oldObj, newObj interface{}, byValue bool) (Responder, error) {
+	err := res.runHooks(func(hook interface{}) error {
+		if before, ok := hook.(BeforeUpdate); ok {
+			return before.BeforeUpdate(oldObj, newObj, req)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updated := newObj
+	if byValue {
+		updated = reflect.ValueOf(newObj).Elem().Interface()
+	}
+
+	response, err := source.Update(updated, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = res.runHooks(func(hook interface{}) error {
+		if after, ok := hook.(AfterUpdate); ok {
+			return after.AfterUpdate(hookResult(response, updated), req)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// delete checks the preconditions of the request and calls Delete of the source surrounded
+// by the delete hooks
+func (res *resource) delete(source ResourceDeleter, c APIContexter, r *http.Request, id string) (Responder, error) {
+	req := buildRequest(c, r)
+
+	// the preconditions need the current version, which is only loaded if there are any
+	if res.hasPreconditions(r) {
+		getter, ok := source.(ResourceGetter)
+		if !ok {
+			return nil, NewHTTPError(nil, fmt.Sprintf("The preconditions can not be checked, resource %s does not implement the ResourceGetter interface", res.name), http.StatusPreconditionFailed)
+		}
+
+		obj, err := getter.FindOne(id, req)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := res.checkPreconditions(r, obj.Result()); err != nil {
+			return nil, err
+		}
+	}
+
+	err := res.runHooks(func(hook interface{}) error {
+		if before, ok := hook.(BeforeDelete); ok {
+			return before.BeforeDelete(id, req)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := source.Delete(id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = res.runHooks(func(hook interface{}) error {
+		if after, ok := hook.(AfterDelete); ok {
+			return after.AfterDelete(id, req)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
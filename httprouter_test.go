@@ -1,5 +1,5 @@
-//go:build !gingonic && !gorillamux && !echo
-// +build !gingonic,!gorillamux,!echo
+//go:build !gingonic && !gorillamux && !echo && !chi && !fiber
+// +build !gingonic,!gorillamux,!echo,!chi,!fiber
 
 package api2go
 
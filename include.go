@@ -0,0 +1,109 @@
+package api2go
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/manyminds/api2go/jsonapi"
+)
+
+const codeInvalidQueryInclude = "API2GO_INVALID_INCLUDE_QUERY_PARAM"
+
+// getIncludePaths parses the include query parameter of the request, it
+// returns nil if the parameter was not given at all.
+func getIncludePaths(r *http.Request) jsonapi.IncludePaths {
+	values, ok := r.URL.Query()["include"]
+	if !ok {
+		return nil
+	}
+
+	return jsonapi.ParseIncludePaths(values[0])
+}
+
+// references returns the relationships declared by the resource type.
+func (res *resource) references() []jsonapi.Reference {
+	resourceType := res.resourceType
+	if resourceType.Kind() == reflect.Ptr {
+		resourceType = resourceType.Elem()
+	}
+
+	if casted, ok := reflect.New(resourceType).Interface().(jsonapi.MarshalReferences); ok {
+		return casted.GetReferences()
+	}
+
+	return nil
+}
+
+// validateInclude checks that every include path only follows relationships
+// that exist on the resource and on the registered related resources.
+func (res *resource) validateInclude(include jsonapi.IncludePaths) error {
+	invalidPaths := res.invalidIncludePaths(include, "")
+	if len(invalidPaths) == 0 {
+		return nil
+	}
+
+	sort.Strings(invalidPaths)
+	httpError := NewHTTPError(nil, "Some requested include paths were invalid", http.StatusBadRequest)
+	for _, path := range invalidPaths {
+		httpError.Errors = append(httpError.Errors, Error{
+			Status: strconv.Itoa(http.StatusBadRequest),
+			Code:   codeInvalidQueryInclude,
+			Title:  fmt.Sprintf(`Relationship path "%s" does not exist for type "%s"`, path, res.name),
+			Detail: "Please make sure you do only include existing relationships",
+			Source: &ErrorSource{
+				Parameter: "include",
+			},
+		})
+	}
+
+	return httpError
+}
+
+func (res *resource) invalidIncludePaths(include jsonapi.IncludePaths, prefix string) []string {
+	invalidPaths := []string{}
+
+	references := map[string]jsonapi.Reference{}
+	for _, reference := range res.references() {
+		references[reference.Name] = reference
+	}
+
+	for name, children := range include {
+		path := prefix + name
+
+		reference, ok := references[name]
+		if !ok {
+			invalidPaths = append(invalidPaths, path)
+			continue
+		}
+
+		if len(children) == 0 {
+			continue
+		}
+
+		related := res.api.resourceByName(reference.Type)
+		if related == nil {
+			for _, childPath := range children.Paths() {
+				invalidPaths = append(invalidPaths, path+"."+childPath)
+			}
+			continue
+		}
+
+		invalidPaths = append(invalidPaths, related.invalidIncludePaths(children, path+".")...)
+	}
+
+	return invalidPaths
+}
+
+// resourceByName returns the registered resource with the given name or nil.
+func (api *API) resourceByName(name string) *resource {
+	for i := range api.resources {
+		if api.resources[i].name == name {
+			return api.resources[i]
+		}
+	}
+
+	return nil
+}
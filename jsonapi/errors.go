@@ -0,0 +1,163 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError is returned by Unmarshal if the source is not valid JSON. Offset
+// is the number of bytes read before the error occurred.
+type SyntaxError struct {
+	Offset int64
+	Err    error
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying *json.SyntaxError
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// DocumentError is returned by Unmarshal if a member of the document is missing
+// or has a value of the wrong type. Pointer is a JSON pointer to the member.
+type DocumentError struct {
+	Pointer string
+	Err     error
+}
+
+func (e *DocumentError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *DocumentError) Unwrap() error {
+	return e.Err
+}
+
+// MissingTypeError is returned by Unmarshal if a resource object has no type.
+// Pointer is a JSON pointer to the type member of the resource object.
+type MissingTypeError struct {
+	Pointer string
+}
+
+func (e *MissingTypeError) Error() string {
+	return "invalid record, no type was specified"
+}
+
+// TypeMismatchError is returned by Unmarshal if the type of a resource object
+// does not match the type of the target struct. Pointer is a JSON pointer to
+// the type member of the resource object.
+type TypeMismatchError struct {
+	Pointer  string
+	Type     string
+	Expected string
+}
+
+func (e *TypeMismatchError) Error() string {
+	return "Type " + e.Type + " in JSON does not match target struct type " + e.Expected
+}
+
+// RelationshipError is returned by Unmarshal if a relationship has an invalid
+// shape or the target can not store it. Pointer is a JSON pointer to the
+// relationship object.
+type RelationshipError struct {
+	Pointer      string
+	Relationship string
+	Err          error
+}
+
+func (e *RelationshipError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *RelationshipError) Unwrap() error {
+	return e.Err
+}
+
+// EscapePointerToken escapes a reference token of a JSON pointer as described in
+// RFC 6901
+func EscapePointerToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// FieldPointer converts the dotted field path of a json.UnmarshalTypeError into a
+// JSON pointer below prefix
+func FieldPointer(prefix, field string) string {
+	pointer := prefix
+	for _, token := range strings.Split(field, ".") {
+		if token != "" {
+			pointer += "/" + EscapePointerToken(token)
+		}
+	}
+
+	return pointer
+}
+
+// documentError locates the member of the source that could not be decoded into a
+// Document and returns a typed error for it
+func documentError(data []byte, err error) error {
+	if syntaxErr, ok := err.(*json.SyntaxError); ok {
+		return &SyntaxError{Offset: syntaxErr.Offset, Err: syntaxErr}
+	}
+
+	var document struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if rawErr := json.Unmarshal(data, &document); rawErr != nil {
+		return typeError("", rawErr)
+	}
+
+	var records []json.RawMessage
+	if json.Unmarshal(document.Data, &records) == nil {
+		for i, record := range records {
+			if recordErr := recordError("/data/"+strconv.Itoa(i), record); recordErr != nil {
+				return recordErr
+			}
+		}
+	} else if recordErr := recordError("/data", document.Data); recordErr != nil {
+		return recordErr
+	}
+
+	return typeError("", err)
+}
+
+// recordError returns a typed error for the first member of the resource object
+// at pointer that can not be decoded
+func recordError(pointer string, record json.RawMessage) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(record, &members); err != nil {
+		return &DocumentError{Pointer: pointer, Err: err}
+	}
+
+	var relationships map[string]json.RawMessage
+	if err := json.Unmarshal(members["relationships"], &relationships); err == nil {
+		for name, relationship := range relationships {
+			if err := json.Unmarshal(relationship, &Relationship{}); err != nil {
+				return &RelationshipError{
+					Pointer:      pointer + "/relationships/" + EscapePointerToken(name),
+					Relationship: name,
+					Err:          err,
+				}
+			}
+		}
+	}
+
+	if err := json.Unmarshal(record, &Data{}); err != nil {
+		return typeError(pointer, err)
+	}
+
+	return nil
+}
+
+func typeError(pointer string, err error) error {
+	if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+		return &DocumentError{Pointer: FieldPointer(pointer, typeErr.Field), Err: err}
+	}
+
+	return &DocumentError{Pointer: pointer, Err: err}
+}
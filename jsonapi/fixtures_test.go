@@ -588,6 +588,38 @@ func (i PrefixServerInformation) GetPrefix() string {
 	return prefix
 }
 
+type RelationshipPathServerInformation struct {
+	CompleteServerInformation
+}
+
+func (i RelationshipPathServerInformation) GetRelationshipPath(structType, name string) string {
+	if structType == "posts" && name == "comments" {
+		return "replies"
+	}
+
+	return name
+}
+
+type ResourcePathServerInformation struct {
+	CompleteServerInformation
+}
+
+func (i ResourcePathServerInformation) GetResourcePath(structType string, element MarshalIdentifier) string {
+	if structType == "posts" {
+		return "blogs/" + element.GetID().ID + "/posts"
+	}
+
+	return structType
+}
+
+type UnknownPathServerInformation struct {
+	CompleteServerInformation
+}
+
+func (i UnknownPathServerInformation) GetResourcePath(structType string, element MarshalIdentifier) string {
+	return ""
+}
+
 type CustomLinksPost struct{}
 
 func (n CustomLinksPost) GetID() Identifier {
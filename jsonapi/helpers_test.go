@@ -22,5 +22,15 @@ var _ = Describe("StringHelpers", func() {
 				Expect(Jsonify("RAM")).To(Equal("ram"))
 			})
 		})
+
+		Context("FieldPointer", func() {
+			It("escapes the tokens of the field path", func() {
+				Expect(FieldPointer("/data/attributes", "a/b.c~d")).To(Equal("/data/attributes/a~1b/c~0d"))
+			})
+
+			It("returns the prefix without field", func() {
+				Expect(FieldPointer("/data", "")).To(Equal("/data"))
+			})
+		})
 	})
 })
@@ -0,0 +1,134 @@
+package jsonapi
+
+import (
+	"sort"
+	"strings"
+)
+
+// IncludePaths is a tree of relationship names as requested by the `include`
+// query parameter. Every key is the name of a relationship and its value holds
+// the relationships that should be included for the related resources.
+//
+// For example `include=author,comments.author` results in:
+//
+//	IncludePaths{
+//		"author":   IncludePaths{},
+//		"comments": IncludePaths{"author": IncludePaths{}},
+//	}
+//
+// A nil IncludePaths means that no include parameter was given, in which case
+// all structs returned by GetReferencedStructs are embedded.
+type IncludePaths map[string]IncludePaths
+
+// ParseIncludePaths parses a comma separated list of dot separated relationship
+// paths into an IncludePaths tree. Empty paths are ignored.
+func ParseIncludePaths(value string) IncludePaths {
+	result := IncludePaths{}
+
+	for _, path := range strings.Split(value, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		node := result
+		for _, name := range strings.Split(path, ".") {
+			child, ok := node[name]
+			if !ok {
+				child = IncludePaths{}
+				node[name] = child
+			}
+			node = child
+		}
+	}
+
+	return result
+}
+
+// Paths returns all leaf paths of the tree in their dot separated form, sorted
+// alphabetically.
+func (i IncludePaths) Paths() []string {
+	result := []string{}
+
+	for name, children := range i {
+		if len(children) == 0 {
+			result = append(result, name)
+			continue
+		}
+
+		for _, path := range children.Paths() {
+			result = append(result, name+"."+path)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// selectIncludes returns the referenced structs of all input elements that
+// belong to one of the requested relationships, followed by their own requested
+// includes. The order of GetReferencedStructs is preserved.
+func selectIncludes(input []MarshalIdentifier, include IncludePaths) []MarshalIdentifier {
+	var result []MarshalIdentifier
+
+	if len(include) == 0 {
+		return result
+	}
+
+	for _, element := range input {
+		included, ok := element.(MarshalIncludedRelations)
+		if !ok {
+			continue
+		}
+
+		relationNames := getIncludedRelationNames(included)
+		for _, referencedStruct := range included.GetReferencedStructs() {
+			for _, name := range relationNames(referencedStruct) {
+				children, ok := include[name]
+				if !ok {
+					continue
+				}
+
+				result = append(result, referencedStruct)
+				result = append(result, selectIncludes([]MarshalIdentifier{referencedStruct}, children)...)
+			}
+		}
+	}
+
+	return result
+}
+
+// getIncludedRelationNames returns a lookup function that resolves the names
+// of the relationships a referenced struct belongs to. If the element
+// implements MarshalLinkedRelations its reference ids are used, otherwise the
+// relationships are matched by type only.
+func getIncludedRelationNames(element MarshalIncludedRelations) func(MarshalIdentifier) []string {
+	namesByType := map[string][]string{}
+	for _, reference := range element.GetReferences() {
+		namesByType[reference.Type] = append(namesByType[reference.Type], reference.Name)
+	}
+
+	linked, ok := element.(MarshalLinkedRelations)
+	if !ok {
+		return func(referencedStruct MarshalIdentifier) []string {
+			return namesByType[getStructType(referencedStruct)]
+		}
+	}
+
+	namesByID := map[Identifier][]string{}
+	for _, referenceID := range linked.GetReferencedIDs() {
+		key := Identifier{ID: referenceID.ID, LID: referenceID.LID, Name: referenceID.Type}
+		namesByID[key] = append(namesByID[key], referenceID.Name)
+	}
+
+	return func(referencedStruct MarshalIdentifier) []string {
+		id := referencedStruct.GetID()
+		structType := getStructType(referencedStruct)
+		if names, ok := namesByID[Identifier{ID: id.ID, LID: id.LID, Name: structType}]; ok {
+			return names
+		}
+
+		return namesByType[structType]
+	}
+}
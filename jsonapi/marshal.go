@@ -108,6 +108,21 @@ type ServerInformation interface {
 	GetPrefix() string
 }
 
+// The ResourcePathResolver interface can be implemented by a ServerInformation if
+// resources are not served below the prefix at the path of their type, for example nested
+// resources. GetResourcePath returns the path of the collection of element below the
+// prefix. An empty path means that it is unknown, the links of element are left out then
+// and GetCustomMeta is called with an empty base URL.
+type ResourcePathResolver interface {
+	GetResourcePath(structType string, element MarshalIdentifier) string
+}
+
+// The RelationshipPathResolver interface can be implemented by a ServerInformation if the
+// self and related links of relationships do not end with the name of the relationship.
+type RelationshipPathResolver interface {
+	GetRelationshipPath(structType, name string) string
+}
+
 // MarshalWithURLs can be used to pass along a ServerInformation implementor.
 func MarshalWithURLs(data interface{}, information ServerInformation) ([]byte, error) {
 	document, err := MarshalToStruct(data, information)
@@ -136,15 +151,23 @@ func Marshal(data interface{}) ([]byte, error) {
 // you want to extract or extend parts of the document. You should directly use
 // Marshal to get a []byte with JSON in it.
 func MarshalToStruct(data interface{}, information ServerInformation) (*Document, error) {
+	return MarshalToStructWithIncludes(data, information, nil)
+}
+
+// MarshalToStructWithIncludes does the same as MarshalToStruct but only embeds
+// the referenced structs of the relationships requested in `include` into the
+// included section of the document. If `include` is nil, all referenced structs
+// are embedded.
+func MarshalToStructWithIncludes(data interface{}, information ServerInformation, include IncludePaths) (*Document, error) {
 	if data == nil {
 		return &Document{}, nil
 	}
 
 	switch reflect.TypeOf(data).Kind() {
 	case reflect.Slice:
-		return marshalSlice(data, information)
+		return marshalSlice(data, information, include)
 	case reflect.Struct, reflect.Ptr:
-		return marshalStruct(data.(MarshalIdentifier), information)
+		return marshalStruct(data.(MarshalIdentifier), information, include)
 	default:
 		return nil, errors.New("Marshal only accepts slice, struct or ptr types")
 	}
@@ -171,12 +194,12 @@ func recursivelyEmbedIncludes(input []MarshalIdentifier) []MarshalIdentifier {
 	return referencedStructs
 }
 
-func marshalSlice(data interface{}, information ServerInformation) (*Document, error) {
+func marshalSlice(data interface{}, information ServerInformation, include IncludePaths) (*Document, error) {
 	result := &Document{}
 
 	val := reflect.ValueOf(data)
 	dataElements := make([]Data, val.Len())
-	var referencedStructs []MarshalIdentifier
+	var elements, referencedStructs []MarshalIdentifier
 
 	for i := 0; i < val.Len(); i++ {
 		k := val.Index(i).Interface()
@@ -190,13 +213,21 @@ func marshalSlice(data interface{}, information ServerInformation) (*Document, e
 			return nil, err
 		}
 
+		elements = append(elements, element)
+
 		included, ok := k.(MarshalIncludedRelations)
 		if ok {
 			referencedStructs = append(referencedStructs, included.GetReferencedStructs()...)
 		}
 	}
 
-	allReferencedStructs := recursivelyEmbedIncludes(referencedStructs)
+	var allReferencedStructs []MarshalIdentifier
+	if include != nil {
+		allReferencedStructs = selectIncludes(elements, include)
+	} else {
+		allReferencedStructs = recursivelyEmbedIncludes(referencedStructs)
+	}
+
 	includedElements, err := filterDuplicates(allReferencedStructs, information)
 	if err != nil {
 		return nil, err
@@ -258,11 +289,12 @@ func marshalData(element MarshalIdentifier, data *Data, information ServerInform
 	data.Type = getStructType(element)
 
 	if information != nil {
-		if customLinks, ok := element.(MarshalCustomLinks); ok {
+		// the base is empty if the path of the element is unknown
+		base := getLinkBaseURL(element, information)
+		if customLinks, ok := element.(MarshalCustomLinks); ok && base != "" {
 			if data.Links == nil {
 				data.Links = make(Links)
 			}
-			base := getLinkBaseURL(element, information)
 			for k, v := range customLinks.GetCustomLinks(base) {
 				if _, ok := data.Links[k]; !ok {
 					data.Links[k] = v
@@ -410,7 +442,15 @@ func getLinkBaseURL(element MarshalIdentifier, information ServerInformation) st
 		prefix += "/" + namespace
 	}
 
-	return fmt.Sprintf("%s/%s/%s", prefix, getStructType(element), element.GetID().ID)
+	path := getStructType(element)
+	if resolver, ok := information.(ResourcePathResolver); ok {
+		path = resolver.GetResourcePath(path, element)
+		if path == "" {
+			return ""
+		}
+	}
+
+	return fmt.Sprintf("%s/%s/%s", prefix, path, element.GetID().ID)
 }
 
 func getLinksForServerInformation(relationer MarshalLinkedRelations, name string, information ServerInformation) Links {
@@ -418,16 +458,25 @@ func getLinksForServerInformation(relationer MarshalLinkedRelations, name string
 		return nil
 	}
 
-	links := make(Links)
 	base := getLinkBaseURL(relationer, information)
+	if base == "" {
+		return nil
+	}
 
-	links["self"] = Link{Href: fmt.Sprintf("%s/relationships/%s", base, name)}
-	links["related"] = Link{Href: fmt.Sprintf("%s/%s", base, name)}
+	links := make(Links)
+
+	path := name
+	if resolver, ok := information.(RelationshipPathResolver); ok {
+		path = resolver.GetRelationshipPath(getStructType(relationer), name)
+	}
+
+	links["self"] = Link{Href: fmt.Sprintf("%s/relationships/%s", base, path)}
+	links["related"] = Link{Href: fmt.Sprintf("%s/%s", base, path)}
 
 	return links
 }
 
-func marshalStruct(data MarshalIdentifier, information ServerInformation) (*Document, error) {
+func marshalStruct(data MarshalIdentifier, information ServerInformation, include IncludePaths) (*Document, error) {
 	var contentData Data
 
 	err := marshalData(data, &contentData, information)
@@ -443,7 +492,14 @@ func marshalStruct(data MarshalIdentifier, information ServerInformation) (*Docu
 
 	included, ok := data.(MarshalIncludedRelations)
 	if ok {
-		included, err := filterDuplicates(recursivelyEmbedIncludes(included.GetReferencedStructs()), information)
+		var referencedStructs []MarshalIdentifier
+		if include != nil {
+			referencedStructs = selectIncludes([]MarshalIdentifier{data}, include)
+		} else {
+			referencedStructs = recursivelyEmbedIncludes(included.GetReferencedStructs())
+		}
+
+		included, err := filterDuplicates(referencedStructs, information)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,91 @@
+package jsonapi
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Marshalling with include paths", func() {
+	var (
+		post     Post
+		included func(*Document) []string
+	)
+
+	BeforeEach(func() {
+		subComment := Comment{ID: 3, Text: "Third!", SubCommentsEmpty: true}
+		comment1 := Comment{ID: 1, Text: "First!", SubComments: []Comment{subComment}}
+		comment2 := Comment{ID: 2, Text: "Second!", SubCommentsEmpty: true}
+		author := User{ID: 1, Name: "Test Author"}
+		post = Post{ID: 1, Title: "Foobar", Comments: []Comment{comment1, comment2}, Author: &author}
+
+		included = func(document *Document) []string {
+			result := []string{}
+			for _, data := range document.Included {
+				result = append(result, data.Type+":"+data.ID)
+			}
+			return result
+		}
+	})
+
+	Context("ParseIncludePaths", func() {
+		It("parses nested paths into a tree", func() {
+			paths := ParseIncludePaths("author,comments.author, comments.comments,")
+			Expect(paths).To(Equal(IncludePaths{
+				"author": IncludePaths{},
+				"comments": IncludePaths{
+					"author":   IncludePaths{},
+					"comments": IncludePaths{},
+				},
+			}))
+		})
+
+		It("returns an empty tree for an empty value", func() {
+			paths := ParseIncludePaths("")
+			Expect(paths).ToNot(BeNil())
+			Expect(paths).To(BeEmpty())
+		})
+
+		It("returns all leaf paths", func() {
+			paths := ParseIncludePaths("comments.comments,author")
+			Expect(paths.Paths()).To(Equal([]string{"author", "comments.comments"}))
+		})
+	})
+
+	It("includes everything without include paths", func() {
+		document, err := MarshalToStructWithIncludes(post, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(included(document)).To(Equal([]string{"users:1", "comments:1", "comments:2", "comments:3"}))
+	})
+
+	It("includes nothing with empty include paths", func() {
+		document, err := MarshalToStructWithIncludes(post, nil, IncludePaths{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(document.Included).To(BeNil())
+	})
+
+	It("only includes the requested relationship", func() {
+		document, err := MarshalToStructWithIncludes(post, nil, ParseIncludePaths("author"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(included(document)).To(Equal([]string{"users:1"}))
+	})
+
+	It("does not include nested relationships that were not requested", func() {
+		document, err := MarshalToStructWithIncludes(post, nil, ParseIncludePaths("comments"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(included(document)).To(Equal([]string{"comments:1", "comments:2"}))
+	})
+
+	It("includes nested relationships", func() {
+		document, err := MarshalToStructWithIncludes(post, nil, ParseIncludePaths("comments.comments"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(included(document)).To(Equal([]string{"comments:1", "comments:3", "comments:2"}))
+	})
+
+	It("filters includes for slices", func() {
+		otherAuthor := User{ID: 2, Name: "Other Author"}
+		posts := []Post{post, {ID: 2, Title: "Other", Author: &otherAuthor}}
+		document, err := MarshalToStructWithIncludes(posts, nil, ParseIncludePaths("author"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(included(document)).To(Equal([]string{"users:1", "users:2"}))
+	})
+})
@@ -838,6 +838,38 @@ var _ = Describe("Marshalling", func() {
 			Expect(i).To(MatchJSON(expected))
 		})
 
+		It("uses the relationship paths of the server information in links", func() {
+			post := Post{ID: 1, Comments: []Comment{}, CommentsIDs: []int{1}}
+			i, err := MarshalToStruct(post, RelationshipPathServerInformation{})
+			Expect(err).To(BeNil())
+
+			relationships := i.Data.DataObject.Relationships
+			Expect(relationships["comments"].Links).To(Equal(Links{
+				"self":    Link{Href: "http://my.domain/v1/posts/1/relationships/replies"},
+				"related": Link{Href: "http://my.domain/v1/posts/1/replies"},
+			}))
+			Expect(relationships["author"].Links["related"]).To(Equal(Link{Href: "http://my.domain/v1/posts/1/author"}))
+		})
+
+		It("uses the resource paths of the server information in links", func() {
+			post := Post{ID: 1, Comments: []Comment{}, CommentsIDs: []int{1}}
+			i, err := MarshalToStruct(post, ResourcePathServerInformation{})
+			Expect(err).To(BeNil())
+
+			Expect(i.Data.DataObject.Relationships["comments"].Links).To(Equal(Links{
+				"self":    Link{Href: "http://my.domain/v1/blogs/1/posts/1/relationships/comments"},
+				"related": Link{Href: "http://my.domain/v1/blogs/1/posts/1/comments"},
+			}))
+		})
+
+		It("leaves out the links if the resource path is unknown", func() {
+			post := Post{ID: 1, Comments: []Comment{}, CommentsIDs: []int{1}}
+			i, err := MarshalToStruct(post, UnknownPathServerInformation{})
+			Expect(err).To(BeNil())
+
+			Expect(i.Data.DataObject.Relationships["comments"].Links).To(BeNil())
+		})
+
 		It("prefers nested structs when given both, structs and IDs", func() {
 			comment := Comment{ID: 1, SubCommentsEmpty: true}
 			author := User{ID: 1, Name: "Tester"}
@@ -0,0 +1,164 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// StreamEncoder writes a document with an array of resource objects as primary data
+// to a writer. Every resource object is written as soon as it is encoded, so large
+// collections do not have to be held in memory. Only the included resources are
+// collected, they are written by Close.
+//
+//	encoder := jsonapi.NewStreamEncoder(w, information, nil)
+//	for _, user := range users {
+//		if err := encoder.Encode(user); err != nil {
+//			return err
+//		}
+//	}
+//	return encoder.Close(nil, nil)
+type StreamEncoder struct {
+	// Filter is called with every resource object, including the included ones, before
+	// it is written. It can change the object or stop the stream with an error.
+	Filter func(data *Data) error
+
+	w           io.Writer
+	information ServerInformation
+	include     IncludePaths
+	started     bool
+	closed      bool
+	included    []Data
+	seen        map[string]map[Identifier]bool
+}
+
+// NewStreamEncoder returns an encoder that writes to w. information and include work
+// like for MarshalToStructWithIncludes, both can be nil.
+func NewStreamEncoder(w io.Writer, information ServerInformation, include IncludePaths) *StreamEncoder {
+	return &StreamEncoder{
+		w:           w,
+		information: information,
+		include:     include,
+		seen:        map[string]map[Identifier]bool{},
+	}
+}
+
+// Encode writes element as the next resource object of the primary data. Nothing is
+// written if an error is returned.
+func (e *StreamEncoder) Encode(element MarshalIdentifier) error {
+	if e.closed {
+		return errors.New("encoder has already been closed")
+	}
+
+	var data Data
+	if err := marshalData(element, &data, e.information); err != nil {
+		return err
+	}
+
+	encoded, err := e.encodeData(&data)
+	if err != nil {
+		return err
+	}
+
+	if err := e.collectIncludes(element); err != nil {
+		return err
+	}
+
+	prefix := ","
+	if !e.started {
+		prefix = `{"data":[`
+		e.started = true
+	}
+
+	_, err = e.w.Write(append([]byte(prefix), encoded...))
+	return err
+}
+
+// Close finishes the document with the included resources and the given top-level
+// links and meta, both can be nil. A document without resource objects has an empty
+// data array.
+func (e *StreamEncoder) Close(links Links, meta map[string]interface{}) error {
+	if e.closed {
+		return errors.New("encoder has already been closed")
+	}
+	e.closed = true
+
+	result := []byte("]")
+	if !e.started {
+		result = []byte(`{"data":[]`)
+	}
+
+	members := []struct {
+		name  string
+		value interface{}
+		empty bool
+	}{
+		{"included", e.included, len(e.included) == 0},
+		{"links", links, len(links) == 0},
+		{"meta", meta, len(meta) == 0},
+	}
+	for _, member := range members {
+		if member.empty {
+			continue
+		}
+
+		encoded, err := json.Marshal(member.value)
+		if err != nil {
+			return err
+		}
+
+		result = append(result, `,"`+member.name+`":`...)
+		result = append(result, encoded...)
+	}
+
+	_, err := e.w.Write(append(result, '}'))
+	return err
+}
+
+// collectIncludes adds the included resources of element that have not been added yet
+func (e *StreamEncoder) collectIncludes(element MarshalIdentifier) error {
+	var referencedStructs []MarshalIdentifier
+	if e.include != nil {
+		referencedStructs = selectIncludes([]MarshalIdentifier{element}, e.include)
+	} else if included, ok := element.(MarshalIncludedRelations); ok {
+		referencedStructs = recursivelyEmbedIncludes(included.GetReferencedStructs())
+	}
+
+	for _, referencedStruct := range referencedStructs {
+		structType := getStructType(referencedStruct)
+		id := referencedStruct.GetID()
+		if e.seen[structType] == nil {
+			e.seen[structType] = map[Identifier]bool{}
+		}
+
+		if e.seen[structType][id] {
+			continue
+		}
+
+		var data Data
+		if err := marshalData(referencedStruct, &data, e.information); err != nil {
+			return err
+		}
+
+		if e.Filter != nil {
+			if err := e.Filter(&data); err != nil {
+				return err
+			}
+		}
+
+		e.included = append(e.included, data)
+		e.seen[structType][id] = true
+	}
+
+	return nil
+}
+
+func (e *StreamEncoder) encodeData(data *Data) ([]byte, error) {
+	if e.Filter != nil {
+		if err := e.Filter(data); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(data)
+}
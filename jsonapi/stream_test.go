@@ -0,0 +1,101 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StreamEncoder", func() {
+	var (
+		posts  []Post
+		buffer *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		author := User{ID: 1, Name: "Test Author"}
+		comment1 := Comment{ID: 1, Text: "First!", SubCommentsEmpty: true}
+		comment2 := Comment{ID: 2, Text: "Second!", SubCommentsEmpty: true}
+		posts = []Post{
+			{ID: 1, Title: "Foobar", Comments: []Comment{comment1, comment2}, Author: &author},
+			{ID: 2, Title: "Bar", Comments: []Comment{comment2}, Author: &author},
+		}
+		buffer = &bytes.Buffer{}
+	})
+
+	encode := func(encoder *StreamEncoder) {
+		for _, post := range posts {
+			Expect(encoder.Encode(post)).To(Succeed())
+		}
+	}
+
+	It("writes the same document as Marshal", func() {
+		encoder := NewStreamEncoder(buffer, nil, nil)
+		encode(encoder)
+		Expect(buffer.String()).To(HavePrefix(`{"data":[{`))
+		Expect(encoder.Close(nil, nil)).To(Succeed())
+
+		expected, err := Marshal(posts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buffer.String()).To(MatchJSON(expected))
+	})
+
+	It("writes every resource object immediately", func() {
+		encoder := NewStreamEncoder(buffer, nil, ParseIncludePaths(""))
+		Expect(encoder.Encode(posts[0])).To(Succeed())
+		first := buffer.Len()
+		Expect(first).To(BeNumerically(">", 0))
+
+		Expect(encoder.Encode(posts[1])).To(Succeed())
+		Expect(buffer.Len()).To(BeNumerically(">", first))
+	})
+
+	It("writes only the requested includes without duplicates", func() {
+		encoder := NewStreamEncoder(buffer, nil, ParseIncludePaths("author"))
+		encode(encoder)
+		Expect(encoder.Close(nil, nil)).To(Succeed())
+
+		var document Document
+		Expect(json.Unmarshal(buffer.Bytes(), &document)).To(Succeed())
+		Expect(document.Data.DataArray).To(HaveLen(2))
+		Expect(document.Included).To(HaveLen(1))
+		Expect(document.Included[0].Type).To(Equal("users"))
+	})
+
+	It("writes links and meta", func() {
+		encoder := NewStreamEncoder(buffer, nil, ParseIncludePaths(""))
+		Expect(encoder.Close(Links{"next": Link{Href: "/posts?page[number]=2"}}, map[string]interface{}{"total": 2})).To(Succeed())
+		Expect(buffer.String()).To(MatchJSON(`{"data": [], "links": {"next": "/posts?page[number]=2"}, "meta": {"total": 2}}`))
+	})
+
+	It("applies the filter to all resource objects", func() {
+		encoder := NewStreamEncoder(buffer, nil, ParseIncludePaths("author"))
+		encoder.Filter = func(data *Data) error {
+			data.Attributes = json.RawMessage(`{}`)
+			return nil
+		}
+		encode(encoder)
+		Expect(encoder.Close(nil, nil)).To(Succeed())
+		Expect(buffer.String()).ToNot(ContainSubstring("Foobar"))
+		Expect(buffer.String()).ToNot(ContainSubstring("Test Author"))
+	})
+
+	It("writes nothing for elements the filter rejects", func() {
+		encoder := NewStreamEncoder(buffer, nil, nil)
+		encoder.Filter = func(data *Data) error {
+			return errors.New("rejected")
+		}
+		Expect(encoder.Encode(posts[0])).To(MatchError("rejected"))
+		Expect(buffer.Len()).To(Equal(0))
+	})
+
+	It("can not be used after Close", func() {
+		encoder := NewStreamEncoder(buffer, nil, nil)
+		Expect(encoder.Close(nil, nil)).To(Succeed())
+		Expect(encoder.Encode(posts[0])).To(HaveOccurred())
+		Expect(encoder.Close(nil, nil)).To(HaveOccurred())
+	})
+})
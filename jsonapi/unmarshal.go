@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 )
 
 // The UnmarshalIdentifier interface must be implemented to set the ID during
@@ -97,27 +98,35 @@ func Unmarshal(data []byte, target interface{}) error {
 
 	err := json.Unmarshal(data, ctx)
 	if err != nil {
-		return err
+		return documentError(data, err)
 	}
 
 	if ctx.Data == nil {
-		return errors.New(`Source JSON is empty and has no "attributes" payload object`)
+		return &DocumentError{
+			Pointer: "/data",
+			Err:     errors.New(`Source JSON is empty and has no "attributes" payload object`),
+		}
 	}
 
 	if ctx.Data.DataObject != nil {
-		return setDataIntoTarget(ctx.Data.DataObject, target)
+		return setDataIntoTarget(ctx.Data.DataObject, target, "/data")
 	}
 
 	if ctx.Data.DataArray != nil {
 		targetSlice := reflect.TypeOf(target).Elem()
 		if targetSlice.Kind() != reflect.Slice {
-			return fmt.Errorf("Cannot unmarshal array to struct target %s", targetSlice)
+			return &DocumentError{
+				Pointer: "/data",
+				Err:     fmt.Errorf("Cannot unmarshal array to struct target %s", targetSlice),
+			}
 		}
 		targetType := targetSlice.Elem()
 		targetPointer := reflect.ValueOf(target)
 		targetValue := targetPointer.Elem()
 
-		for _, record := range ctx.Data.DataArray {
+		for index, record := range ctx.Data.DataArray {
+			pointer := "/data/" + strconv.Itoa(index)
+
 			// check if there already is an entry with the same id in target slice,
 			// otherwise create a new target and append
 			var targetRecord, emptyValue reflect.Value
@@ -135,13 +144,13 @@ func Unmarshal(data []byte, target interface{}) error {
 
 			if targetRecord == emptyValue || targetRecord.IsNil() {
 				targetRecord = reflect.New(targetType)
-				err := setDataIntoTarget(&record, targetRecord.Interface())
+				err := setDataIntoTarget(&record, targetRecord.Interface(), pointer)
 				if err != nil {
 					return err
 				}
 				targetValue = reflect.Append(targetValue, targetRecord.Elem())
 			} else {
-				err := setDataIntoTarget(&record, targetRecord.Interface())
+				err := setDataIntoTarget(&record, targetRecord.Interface(), pointer)
 				if err != nil {
 					return err
 				}
@@ -154,17 +163,18 @@ func Unmarshal(data []byte, target interface{}) error {
 	return nil
 }
 
-func setDataIntoTarget(data *Data, target interface{}) error {
+// setDataIntoTarget populates target with the resource object at pointer
+func setDataIntoTarget(data *Data, target interface{}, pointer string) error {
 	castedTarget, ok := target.(UnmarshalIdentifier)
 	if !ok {
 		return errors.New("target must implement UnmarshalIdentifier interface")
 	}
 
 	if data.Type == "" {
-		return errors.New("invalid record, no type was specified")
+		return &MissingTypeError{Pointer: pointer + "/type"}
 	}
 
-	err := checkType(data.Type, castedTarget)
+	err := checkType(data.Type, castedTarget, pointer)
 	if err != nil {
 		return err
 	}
@@ -189,18 +199,24 @@ func setDataIntoTarget(data *Data, target interface{}) error {
 		}
 	}
 
-	return setRelationshipIDs(data.Relationships, castedTarget)
+	return setRelationshipIDs(data.Relationships, castedTarget, pointer)
 }
 
 // extracts all found relationships and set's them via SetToOneReferenceID or
 // SetToManyReferenceIDs
-func setRelationshipIDs(relationships map[string]Relationship, target UnmarshalIdentifier) error {
+func setRelationshipIDs(relationships map[string]Relationship, target UnmarshalIdentifier, pointer string) error {
 	for name, rel := range relationships {
+		relationshipPointer := pointer + "/relationships/" + EscapePointerToken(name)
+
 		// if Data is nil, it means that we have an empty toOne relationship
 		if rel.Data == nil {
 			castedToOne, ok := target.(UnmarshalToOneRelations)
 			if !ok {
-				return fmt.Errorf("struct %s does not implement UnmarshalToOneRelations", reflect.TypeOf(target))
+				return &RelationshipError{
+					Pointer:      relationshipPointer,
+					Relationship: name,
+					Err:          fmt.Errorf("struct %s does not implement UnmarshalToOneRelations", reflect.TypeOf(target)),
+				}
 			}
 
 			err := castedToOne.SetToOneReferenceID(name, nil)
@@ -214,7 +230,11 @@ func setRelationshipIDs(relationships map[string]Relationship, target UnmarshalI
 		if rel.Data.DataObject != nil {
 			castedToOne, ok := target.(UnmarshalToOneRelations)
 			if !ok {
-				return fmt.Errorf("struct %s does not implement UnmarshalToOneRelations", reflect.TypeOf(target))
+				return &RelationshipError{
+					Pointer:      relationshipPointer,
+					Relationship: name,
+					Err:          fmt.Errorf("struct %s does not implement UnmarshalToOneRelations", reflect.TypeOf(target)),
+				}
 			}
 			err := castedToOne.SetToOneReferenceID(name, rel.Data.DataObject)
 			if err != nil {
@@ -226,7 +246,11 @@ func setRelationshipIDs(relationships map[string]Relationship, target UnmarshalI
 		if rel.Data.DataArray != nil {
 			castedToMany, ok := target.(UnmarshalToManyRelations)
 			if !ok {
-				return fmt.Errorf("struct %s does not implement UnmarshalToManyRelations", reflect.TypeOf(target))
+				return &RelationshipError{
+					Pointer:      relationshipPointer,
+					Relationship: name,
+					Err:          fmt.Errorf("struct %s does not implement UnmarshalToManyRelations", reflect.TypeOf(target)),
+				}
 			}
 			err := castedToMany.SetToManyReferenceIDs(name, rel.Data.DataArray)
 			if err != nil {
@@ -238,10 +262,10 @@ func setRelationshipIDs(relationships map[string]Relationship, target UnmarshalI
 	return nil
 }
 
-func checkType(incomingType string, target UnmarshalIdentifier) error {
+func checkType(incomingType string, target UnmarshalIdentifier, pointer string) error {
 	actualType := getStructType(target)
 	if incomingType != actualType {
-		return fmt.Errorf("Type %s in JSON does not match target struct type %s", incomingType, actualType)
+		return &TypeMismatchError{Pointer: pointer + "/type", Type: incomingType, Expected: actualType}
 	}
 
 	return nil
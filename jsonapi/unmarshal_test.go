@@ -3,6 +3,7 @@ package jsonapi
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -981,4 +982,62 @@ var _ = Describe("Unmarshal", func() {
 			Expect(expectedPost.ResourceMetadata["post-count"]).To(Equal(simplePostWithMetadata.ResourceMetadata["post-count"]))
 		})
 	})
+
+	Context("when unmarshaling malformed documents", func() {
+		It("returns a SyntaxError with the offset for invalid json", func() {
+			var post SimplePost
+			err := Unmarshal([]byte(`{"data": {"type": "simplePosts",}}`), &post)
+			var syntaxErr *SyntaxError
+			Expect(errors.As(err, &syntaxErr)).To(BeTrue())
+			Expect(syntaxErr.Offset).To(Equal(int64(33)))
+		})
+
+		It("returns a MissingTypeError pointing to the type of the record", func() {
+			var posts []SimplePost
+			err := Unmarshal([]byte(`{"data": [{"type": "simplePosts"}, {"id": "2"}]}`), &posts)
+			Expect(err).To(Equal(&MissingTypeError{Pointer: "/data/1/type"}))
+			Expect(err.Error()).To(Equal("invalid record, no type was specified"))
+		})
+
+		It("returns a TypeMismatchError for a wrong type", func() {
+			var post SimplePost
+			err := Unmarshal([]byte(`{"data": {"type": "posts"}}`), &post)
+			Expect(err).To(Equal(&TypeMismatchError{Pointer: "/data/type", Type: "posts", Expected: "simplePosts"}))
+			Expect(err.Error()).To(Equal("Type posts in JSON does not match target struct type simplePosts"))
+		})
+
+		It("returns a DocumentError for a missing data member", func() {
+			var post SimplePost
+			err := Unmarshal([]byte(`{"meta": {}}`), &post)
+			var documentErr *DocumentError
+			Expect(errors.As(err, &documentErr)).To(BeTrue())
+			Expect(documentErr.Pointer).To(Equal("/data"))
+		})
+
+		It("returns a DocumentError pointing to members with a wrong type", func() {
+			var posts []SimplePost
+			err := Unmarshal([]byte(`{"data": [{"type": "simplePosts"}, {"type": "simplePosts", "id": 2}]}`), &posts)
+			var documentErr *DocumentError
+			Expect(errors.As(err, &documentErr)).To(BeTrue())
+			Expect(documentErr.Pointer).To(Equal("/data/1/id"))
+		})
+
+		It("returns a RelationshipError for relationship data of an invalid shape", func() {
+			var post Post
+			err := Unmarshal([]byte(`{"data": {"type": "posts", "relationships": {"author": {"data": 1}}}}`), &post)
+			var relationshipErr *RelationshipError
+			Expect(errors.As(err, &relationshipErr)).To(BeTrue())
+			Expect(relationshipErr.Pointer).To(Equal("/data/relationships/author"))
+			Expect(relationshipErr.Relationship).To(Equal("author"))
+			Expect(err.Error()).To(Equal("Invalid json for relationship data array/object"))
+		})
+
+		It("returns a RelationshipError if the target does not support the relationship", func() {
+			post := NoRelationshipPosts{}
+			err := Unmarshal([]byte(`{"data": {"type": "posts", "relationships": {"comments/all": {"data": []}}}}`), &post)
+			var relationshipErr *RelationshipError
+			Expect(errors.As(err, &relationshipErr)).To(BeTrue())
+			Expect(relationshipErr.Pointer).To(Equal("/data/relationships/comments~1all"))
+		})
+	})
 })
@@ -0,0 +1,151 @@
+package api2go
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	mediaTypeParamExt     = "ext"
+	mediaTypeParamProfile = "profile"
+	mediaTypeParamQuality = "q"
+)
+
+type negotiationKey struct{}
+
+// negotiation contains the registered extensions and profiles that were
+// requested by the client
+type negotiation struct {
+	extensions []string
+	profiles   []string
+}
+
+// negotiate validates the Content-Type and Accept headers of a request as
+// described in https://jsonapi.org/format/#content-negotiation. The result is
+// stored in the context of the returned request.
+func (api *API) negotiate(r *http.Request) (*http.Request, error) {
+	mediaType, _, err := mime.ParseMediaType(api.ContentType)
+	if err != nil {
+		mediaType = api.ContentType
+	}
+
+	var result negotiation
+
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		requestMediaType, params, err := mime.ParseMediaType(contentType)
+		if err == nil && requestMediaType == mediaType {
+			extensions, profiles, ok := api.supportedMediaTypeParams(params)
+			if !ok {
+				return r, NewHTTPError(nil, fmt.Sprintf("Unsupported media type parameters in %s", contentType), http.StatusUnsupportedMediaType)
+			}
+
+			result.extensions = extensions
+			result.profiles = profiles
+		}
+	}
+
+	if accept := r.Header.Get("Accept"); accept != "" {
+		requested, acceptable := false, false
+
+		for _, entry := range strings.Split(accept, ",") {
+			acceptMediaType, params, err := mime.ParseMediaType(strings.TrimSpace(entry))
+			if err != nil || acceptMediaType != mediaType {
+				continue
+			}
+			requested = true
+
+			if quality, ok := params[mediaTypeParamQuality]; ok {
+				value, err := strconv.ParseFloat(quality, 64)
+				if err != nil || value <= 0 {
+					continue
+				}
+				delete(params, mediaTypeParamQuality)
+			}
+
+			if _, profiles, ok := api.supportedMediaTypeParams(params); ok {
+				result.profiles = appendMissing(result.profiles, profiles...)
+				acceptable = true
+			}
+		}
+
+		// other media types are answered with the JSON:API media type anyway, only
+		// requests that exclusively ask for unsupported JSON:API variants fail
+		if requested && !acceptable {
+			return r, NewHTTPError(nil, fmt.Sprintf("None of the accepted media types %s is supported", accept), http.StatusNotAcceptable)
+		}
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), negotiationKey{}, result)), nil
+}
+
+// supportedMediaTypeParams returns false if params contain anything but the ext
+// and profile parameters or an extension that is not registered. Unknown
+// profiles are ignored.
+func (api *API) supportedMediaTypeParams(params map[string]string) (extensions, profiles []string, ok bool) {
+	for key := range params {
+		if key != mediaTypeParamExt && key != mediaTypeParamProfile {
+			return nil, nil, false
+		}
+	}
+
+	for _, extension := range strings.Fields(params[mediaTypeParamExt]) {
+		if !contains(api.extensions, extension) {
+			return nil, nil, false
+		}
+		extensions = append(extensions, extension)
+	}
+
+	for _, profile := range strings.Fields(params[mediaTypeParamProfile]) {
+		if contains(api.profiles, profile) {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	return extensions, profiles, true
+}
+
+// getNegotiation returns the negotiation result stored by API.negotiate
+func getNegotiation(r *http.Request) negotiation {
+	result, _ := r.Context().Value(negotiationKey{}).(negotiation)
+	return result
+}
+
+// responseContentType adds the applied extensions and profiles of the request
+// to the contentType
+func responseContentType(r *http.Request, contentType string) string {
+	result := getNegotiation(r)
+
+	if len(result.extensions) > 0 {
+		contentType += fmt.Sprintf(`; %s="%s"`, mediaTypeParamExt, strings.Join(result.extensions, " "))
+	}
+
+	if len(result.profiles) > 0 {
+		contentType += fmt.Sprintf(`; %s="%s"`, mediaTypeParamProfile, strings.Join(result.profiles, " "))
+	}
+
+	return contentType
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func appendMissing(values []string, additional ...string) []string {
+	for _, value := range additional {
+		if !contains(values, value) {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,130 @@
+package api2go
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/manyminds/api2go/jsonapi"
+)
+
+// The ParentIdentifier interface can be implemented by resource structs of nested
+// resources, see Under. GetParentIDs returns the ids of the parents by the names of the
+// parameters of the parent path, for example {"storeID": "42"}. They are used in the
+// links of the resource. Without it only the parent ids of the current request are
+// used, which is enough as long as the resource is not included in the responses of
+// other resources.
+type ParentIdentifier interface {
+	GetParentIDs() map[string]string
+}
+
+type parentIDsContextKey struct{}
+
+// path returns the path of the collection of the resource below the api prefix with
+// the parameters of the parent path
+func (res *resource) path() string {
+	if res.config.parent == "" {
+		return res.name
+	}
+
+	return res.config.parent + "/" + res.name
+}
+
+// idParam returns the name of the id parameter in the routes of the resource
+func (res *resource) idParam() string {
+	if res.config.idParam == "" {
+		return "id"
+	}
+
+	return res.config.idParam
+}
+
+// routeParams returns the params of a route with the id of the resource as "id"
+func (res *resource) routeParams(params map[string]string) map[string]string {
+	name := res.idParam()
+	if name == "id" {
+		return params
+	}
+
+	id, ok := params[name]
+	if !ok {
+		return params
+	}
+
+	result := make(map[string]string, len(params)+1)
+	for key, value := range params {
+		result[key] = value
+	}
+	result["id"] = id
+
+	return result
+}
+
+// parentIDs returns the parameters of the parent path from the params of a route
+func (res *resource) parentIDs(params map[string]string) map[string]string {
+	var result map[string]string
+	for _, segment := range strings.Split(res.config.parent, "/") {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+
+		if result == nil {
+			result = map[string]string{}
+		}
+		result[segment[1:]] = params[segment[1:]]
+	}
+
+	return result
+}
+
+// withParentIDs stores the parent ids in the context of r for buildRequest
+func withParentIDs(r *http.Request, ids map[string]string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), parentIDsContextKey{}, ids))
+}
+
+// getParentIDs returns the parent ids of the request
+func getParentIDs(r *http.Request) map[string]string {
+	ids, _ := r.Context().Value(parentIDsContextKey{}).(map[string]string)
+	return ids
+}
+
+// GetResourcePath returns the path of the collection of a resource with the ids of its
+// parents, they are taken from the element if it is a ParentIdentifier and from the
+// request if the element does not return them. The path is empty if the id of a parent
+// is unknown, the links of the element are left out then.
+func (i information) GetResourcePath(structType string, element jsonapi.MarshalIdentifier) string {
+	if i.api == nil {
+		return structType
+	}
+
+	res := i.api.resourceByName(structType)
+	if res == nil || res.config.parent == "" {
+		return structType
+	}
+
+	var ids map[string]string
+	if identifier, ok := element.(ParentIdentifier); ok {
+		ids = identifier.GetParentIDs()
+	}
+
+	segments := strings.Split(res.path(), "/")
+	for index, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+
+		id, ok := ids[segment[1:]]
+		if !ok {
+			id, ok = i.parentIDs[segment[1:]]
+		}
+
+		if !ok {
+			return ""
+		}
+
+		segments[index] = url.PathEscape(id)
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,766 @@
+package api2go
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/manyminds/api2go/jsonapi"
+)
+
+const openAPIVersion = "3.1.0"
+
+// OpenAPIDocument is the root object of an OpenAPI 3.1 document.
+//
+// See https://spec.openapis.org/oas/v3.1.0
+type OpenAPIDocument struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       OpenAPIInfo                `json:"info"`
+	Servers    []OpenAPIServer            `json:"servers,omitempty"`
+	Paths      map[string]OpenAPIPathItem `json:"paths"`
+	Components OpenAPIComponents          `json:"components"`
+}
+
+// OpenAPIInfo contains the metadata of an OpenAPI document
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIServer is a server that serves the documented api
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+// OpenAPIPathItem maps lower case http methods to the operations of a path
+type OpenAPIPathItem map[string]*OpenAPIOperation
+
+// OpenAPIOperation describes a single http method of a path
+type OpenAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter describes a path or query parameter, or references one with Ref
+type OpenAPIParameter struct {
+	Ref         string        `json:"$ref,omitempty"`
+	Name        string        `json:"name,omitempty"`
+	In          string        `json:"in,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required,omitempty"`
+	Style       string        `json:"style,omitempty"`
+	Explode     *bool         `json:"explode,omitempty"`
+	Schema      OpenAPISchema `json:"schema,omitempty"`
+}
+
+// OpenAPIRequestBody describes the request body of an operation
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required,omitempty"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse describes a response of an operation, or references one with Ref
+type OpenAPIResponse struct {
+	Ref         string                      `json:"$ref,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType contains the schema of a request or response body
+type OpenAPIMediaType struct {
+	Schema OpenAPISchema `json:"schema"`
+}
+
+// OpenAPISchema is a JSON Schema (draft 2020-12) as used by OpenAPI 3.1
+type OpenAPISchema map[string]interface{}
+
+// OpenAPIComponents contains the reusable schemas, parameters and responses
+type OpenAPIComponents struct {
+	Schemas    map[string]OpenAPISchema    `json:"schemas"`
+	Parameters map[string]OpenAPIParameter `json:"parameters"`
+	Responses  map[string]OpenAPIResponse  `json:"responses"`
+}
+
+// SetOpenAPIInfo sets the title, version and description of the document returned by OpenAPI
+func (api *API) SetOpenAPIInfo(info OpenAPIInfo) {
+	api.openAPIInfo = info
+}
+
+// OpenAPI generates an OpenAPI 3.1 document of all registered resources. The paths
+// mirror the generated routes, so only the operations that the data sources implement
+// are documented. Attribute schemas are reflected from the json tags of the resource
+// structs, types that implement json.Marshaler are documented without a schema.
+func (api *API) OpenAPI() *OpenAPIDocument {
+	info := api.openAPIInfo
+	if info.Title == "" {
+		info.Title = "api2go"
+	}
+
+	if info.Version == "" {
+		info.Version = "1.0.0"
+	}
+
+	document := &OpenAPIDocument{
+		OpenAPI:    openAPIVersion,
+		Info:       info,
+		Paths:      map[string]OpenAPIPathItem{},
+		Components: api.openAPIComponents(),
+	}
+
+	for i := range api.resources {
+		api.resources[i].addOpenAPI(document)
+	}
+
+	if contains(api.extensions, atomicExtension) {
+		api.addOpenAPIOperations(document)
+	}
+
+	return document
+}
+
+// ServeOpenAPI registers a GET route at the given path below the api prefix that serves
+// the document of OpenAPI as json, for example `api.ServeOpenAPI("openapi.json")`.
+func (api *API) ServeOpenAPI(path string) {
+	api.route(Route{Method: http.MethodGet, Path: api.routePath(strings.Trim(path, "/")), Kind: RouteOpenAPI}, false, nil, func(c APIContexter, w http.ResponseWriter, r *http.Request, _ map[string]string, info information) error {
+		document := api.OpenAPI()
+		if baseURL := info.GetBaseURL(); baseURL != "" {
+			document.Servers = []OpenAPIServer{{URL: baseURL}}
+		}
+
+		result, err := json.Marshal(document)
+		if err != nil {
+			return err
+		}
+
+		writeResult(w, result, http.StatusOK, "application/json")
+		return nil
+	})
+}
+
+func openAPIRef(kind, name string) OpenAPISchema {
+	return OpenAPISchema{"$ref": "#/components/" + kind + "/" + name}
+}
+
+func openAPIContent(contentType string, schema OpenAPISchema) map[string]OpenAPIMediaType {
+	return map[string]OpenAPIMediaType{contentType: {Schema: schema}}
+}
+
+func (api *API) openAPIComponents() OpenAPIComponents {
+	object := OpenAPISchema{"type": "object"}
+	explode := true
+
+	linkObject := OpenAPISchema{
+		"type":     "object",
+		"required": []string{"href"},
+		"properties": map[string]interface{}{
+			"href": OpenAPISchema{"type": "string"},
+			"meta": openAPIRef("schemas", "Meta"),
+		},
+	}
+
+	pageParameter := func(name, description, schemaType string) OpenAPIParameter {
+		return OpenAPIParameter{
+			Name:        "page[" + name + "]",
+			In:          "query",
+			Description: description,
+			Schema:      OpenAPISchema{"type": schemaType},
+		}
+	}
+
+	return OpenAPIComponents{
+		Schemas: map[string]OpenAPISchema{
+			"Meta": {"type": "object", "additionalProperties": true},
+			"Links": {
+				"type": "object",
+				"additionalProperties": OpenAPISchema{
+					"oneOf": []interface{}{OpenAPISchema{"type": "string"}, linkObject, OpenAPISchema{"type": "null"}},
+				},
+			},
+			"ResourceIdentifier": {
+				"type":     "object",
+				"required": []string{"type"},
+				"properties": map[string]interface{}{
+					"type": OpenAPISchema{"type": "string"},
+					"id":   OpenAPISchema{"type": "string"},
+					"lid":  OpenAPISchema{"type": "string"},
+					"meta": openAPIRef("schemas", "Meta"),
+				},
+			},
+			"ToOneRelationship": {
+				"type": "object",
+				"properties": map[string]interface{}{
+					"data": OpenAPISchema{
+						"oneOf": []interface{}{openAPIRef("schemas", "ResourceIdentifier"), OpenAPISchema{"type": "null"}},
+					},
+					"links": openAPIRef("schemas", "Links"),
+					"meta":  openAPIRef("schemas", "Meta"),
+				},
+			},
+			"ToManyRelationship": {
+				"type": "object",
+				"properties": map[string]interface{}{
+					"data":  OpenAPISchema{"type": "array", "items": openAPIRef("schemas", "ResourceIdentifier")},
+					"links": openAPIRef("schemas", "Links"),
+					"meta":  openAPIRef("schemas", "Meta"),
+				},
+			},
+			"Resource": {
+				"type":     "object",
+				"required": []string{"type", "id"},
+				"properties": map[string]interface{}{
+					"type":          OpenAPISchema{"type": "string"},
+					"id":            OpenAPISchema{"type": "string"},
+					"lid":           OpenAPISchema{"type": "string"},
+					"attributes":    object,
+					"relationships": object,
+					"links":         openAPIRef("schemas", "Links"),
+					"meta":          openAPIRef("schemas", "Meta"),
+				},
+			},
+			"Document":           openAPIDocumentSchema(openAPIRef("schemas", "Resource")),
+			"CollectionDocument": openAPIDocumentSchema(OpenAPISchema{"type": "array", "items": openAPIRef("schemas", "Resource")}),
+			"MetaDocument": {
+				"type":       "object",
+				"properties": map[string]interface{}{"meta": openAPIRef("schemas", "Meta")},
+			},
+			"Error": {
+				"type": "object",
+				"properties": map[string]interface{}{
+					"id":     OpenAPISchema{"type": "string"},
+					"links":  openAPIRef("schemas", "Links"),
+					"status": OpenAPISchema{"type": "string"},
+					"code":   OpenAPISchema{"type": "string"},
+					"title":  OpenAPISchema{"type": "string"},
+					"detail": OpenAPISchema{"type": "string"},
+					"source": OpenAPISchema{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"pointer":   OpenAPISchema{"type": "string"},
+							"parameter": OpenAPISchema{"type": "string"},
+						},
+					},
+					"meta": openAPIRef("schemas", "Meta"),
+				},
+			},
+			"ErrorDocument": {
+				"type":     "object",
+				"required": []string{"errors"},
+				"properties": map[string]interface{}{
+					"errors": OpenAPISchema{"type": "array", "items": openAPIRef("schemas", "Error")},
+				},
+			},
+		},
+		Parameters: map[string]OpenAPIParameter{
+			"id": {
+				Name:     "id",
+				In:       "path",
+				Required: true,
+				Schema:   OpenAPISchema{"type": "string"},
+			},
+			"include": {
+				Name:        "include",
+				In:          "query",
+				Description: "Comma separated list of relationship paths to include in the response",
+				Schema:      OpenAPISchema{"type": "string"},
+			},
+			"fields": {
+				Name:        "fields",
+				In:          "query",
+				Description: "Sparse fieldsets, for example fields[posts]=title",
+				Style:       "deepObject",
+				Explode:     &explode,
+				Schema:      OpenAPISchema{"type": "object", "additionalProperties": OpenAPISchema{"type": "string"}},
+			},
+			"pageNumber": pageParameter("number", "Page number, used together with page[size]", "integer"),
+			"pageSize":   pageParameter("size", "Number of resources per page", "integer"),
+			"pageOffset": pageParameter("offset", "Offset of the page, used together with page[limit]", "integer"),
+			"pageLimit":  pageParameter("limit", "Maximum number of resources, used together with page[offset]", "integer"),
+			"pageAfter":  pageParameter("after", "Cursor of the resource the page starts after", "string"),
+			"pageBefore": pageParameter("before", "Cursor of the resource the page ends before", "string"),
+		},
+		Responses: map[string]OpenAPIResponse{
+			"Error": {
+				Description: "Error",
+				Content:     openAPIContent(api.ContentType, openAPIRef("schemas", "ErrorDocument")),
+			},
+		},
+	}
+}
+
+// openAPIDocumentSchema returns the schema of a top level document with the given primary data
+func openAPIDocumentSchema(data OpenAPISchema) OpenAPISchema {
+	return OpenAPISchema{
+		"type":     "object",
+		"required": []string{"data"},
+		"properties": map[string]interface{}{
+			"data":     data,
+			"included": OpenAPISchema{"type": "array", "items": openAPIRef("schemas", "Resource")},
+			"links":    openAPIRef("schemas", "Links"),
+			"meta":     openAPIRef("schemas", "Meta"),
+		},
+	}
+}
+
+// isToManyRelation guesses the relationship type in the same way the marshaller does
+func isToManyRelation(relation jsonapi.Reference) bool {
+	if relation.Relationship == jsonapi.DefaultRelationship {
+		return relation.Name == jsonapi.Pluralize(relation.Name)
+	}
+
+	return relation.Relationship == jsonapi.ToManyRelationship
+}
+
+// resourceObjectSchema returns the schema of a resource object of this resource
+func (res *resource) resourceObjectSchema(required ...string) OpenAPISchema {
+	relationships := map[string]interface{}{}
+	for _, relation := range res.references() {
+		if isToManyRelation(relation) {
+			relationships[relation.Name] = openAPIRef("schemas", "ToManyRelationship")
+		} else {
+			relationships[relation.Name] = openAPIRef("schemas", "ToOneRelationship")
+		}
+	}
+
+	return OpenAPISchema{
+		"type":     "object",
+		"required": required,
+		"properties": map[string]interface{}{
+			"type":          OpenAPISchema{"type": "string", "const": res.name},
+			"id":            OpenAPISchema{"type": "string"},
+			"lid":           OpenAPISchema{"type": "string"},
+			"attributes":    openAPIRef("schemas", res.name+"Attributes"),
+			"relationships": OpenAPISchema{"type": "object", "properties": relationships},
+			"links":         openAPIRef("schemas", "Links"),
+			"meta":          openAPIRef("schemas", "Meta"),
+		},
+	}
+}
+
+// collectionParameters returns the query parameters that are supported when listing
+// this resource
+func (res *resource) collectionParameters() []OpenAPIParameter {
+	parameters := []OpenAPIParameter{}
+	if len(res.references()) > 0 {
+		parameters = append(parameters, OpenAPIParameter{Ref: "#/components/parameters/include"})
+	}
+	parameters = append(parameters, OpenAPIParameter{Ref: "#/components/parameters/fields"})
+
+	if sortable, ok := res.source.(SortableFields); ok {
+		parameters = append(parameters, OpenAPIParameter{
+			Name:        "sort",
+			In:          "query",
+			Description: "Comma separated list of fields to sort by, prefix a field with - to sort descending. Supported fields: " + strings.Join(sortable.SortableFields(), ", "),
+			Schema:      OpenAPISchema{"type": "string"},
+		})
+	}
+
+	if filterable, ok := res.source.(FilterableFields); ok {
+		fields := filterable.FilterableFields()
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			for _, operator := range fields[name] {
+				parameters = append(parameters, OpenAPIParameter{
+					Name:        Filter{Field: name, Operator: operator}.Parameter(),
+					In:          "query",
+					Description: "Comma separated list of values",
+					Schema:      OpenAPISchema{"type": "string"},
+				})
+			}
+		}
+	}
+
+	pages := []string{}
+	if _, ok := res.source.(PaginatedFindAll); ok {
+		pages = append(pages, "pageNumber", "pageSize", "pageOffset", "pageLimit")
+	}
+	if _, ok := res.source.(CursorPaginatedFindAll); ok {
+		pages = appendMissing(pages, "pageSize", "pageAfter", "pageBefore")
+	}
+	for _, page := range pages {
+		parameters = append(parameters, OpenAPIParameter{Ref: "#/components/parameters/" + page})
+	}
+
+	return parameters
+}
+
+// addOpenAPI adds the schemas and paths of all routes that addResource generates for
+// this resource
+func (res *resource) addOpenAPI(document *OpenAPIDocument) {
+	name := res.name
+	contentType := res.api.ContentType
+	schemas := document.Components.Schemas
+
+	resourceType := res.resourceType
+	if resourceType.Kind() == reflect.Ptr {
+		resourceType = resourceType.Elem()
+	}
+
+	schemas[name+"Attributes"] = openAPITypeSchema(resourceType, map[reflect.Type]bool{})
+	schemas[name+"Resource"] = res.resourceObjectSchema("type", "id")
+	schemas[name+"Document"] = openAPIDocumentSchema(openAPIRef("schemas", name+"Resource"))
+	schemas[name+"CollectionDocument"] = openAPIDocumentSchema(OpenAPISchema{"type": "array", "items": openAPIRef("schemas", name+"Resource")})
+
+	errorResponse := OpenAPIResponse{Ref: "#/components/responses/Error"}
+	idParameter := OpenAPIParameter{Ref: "#/components/parameters/id"}
+	if res.idParam() != "id" {
+		idParameter = openAPIPathParameter(res.idParam())
+	}
+
+	var parentParameters []OpenAPIParameter
+	for _, segment := range strings.Split(res.config.parent, "/") {
+		if strings.HasPrefix(segment, ":") {
+			parentParameters = append(parentParameters, openAPIPathParameter(segment[1:]))
+		}
+	}
+	includeParameters := []OpenAPIParameter{{Ref: "#/components/parameters/fields"}}
+	if len(res.references()) > 0 {
+		includeParameters = append([]OpenAPIParameter{{Ref: "#/components/parameters/include"}}, includeParameters...)
+	}
+
+	documentResponse := func(description, schema string) OpenAPIResponse {
+		return OpenAPIResponse{Description: description, Content: openAPIContent(contentType, openAPIRef("schemas", schema))}
+	}
+
+	operation := func(id, summary string, parameters []OpenAPIParameter, responses map[string]OpenAPIResponse) *OpenAPIOperation {
+		responses["default"] = errorResponse
+		if len(parentParameters) > 0 {
+			parameters = append(append([]OpenAPIParameter{}, parentParameters...), parameters...)
+		}
+
+		return &OpenAPIOperation{
+			OperationID: name + "." + id,
+			Summary:     summary,
+			Tags:        []string{name},
+			Parameters:  parameters,
+			Responses:   responses,
+		}
+	}
+
+	withBody := func(op *OpenAPIOperation, schema OpenAPISchema) *OpenAPIOperation {
+		op.RequestBody = &OpenAPIRequestBody{Required: true, Content: openAPIContent(contentType, schema)}
+		return op
+	}
+
+	baseURL := openAPIPath(res.api.routePath(res.path()))
+	elementURL := baseURL + "/{" + res.idParam() + "}"
+	collection := OpenAPIPathItem{}
+	single := OpenAPIPathItem{}
+
+	_, findAll := res.source.(FindAll)
+	_, paginated := res.source.(PaginatedFindAll)
+	_, cursor := res.source.(CursorPaginatedFindAll)
+	allows := res.config.allows
+	if (findAll || paginated || cursor) && allows(OperationList, "") {
+		collection["get"] = operation(string(OperationList), "List "+name, res.collectionParameters(), map[string]OpenAPIResponse{
+			"200": documentResponse("The "+name, name+"CollectionDocument"),
+		})
+	}
+
+	if _, ok := res.source.(ResourceCreator); ok && allows(OperationCreate, "") {
+		schemas[name+"CreateDocument"] = OpenAPISchema{
+			"type":       "object",
+			"required":   []string{"data"},
+			"properties": map[string]interface{}{"data": res.resourceObjectSchema("type")},
+		}
+
+		collection["post"] = withBody(operation(string(OperationCreate), "Create a "+name+" resource", includeParameters, map[string]OpenAPIResponse{
+			"201": documentResponse("The created resource", name+"Document"),
+			"202": {Description: "Accepted"},
+			"204": {Description: "Created with the id supplied by the client"},
+		}), openAPIRef("schemas", name+"CreateDocument"))
+	}
+
+	if _, ok := res.source.(ResourceGetter); ok && allows(OperationRead, "") {
+		single["get"] = operation(string(OperationRead), "Read a "+name+" resource", append([]OpenAPIParameter{idParameter}, includeParameters...), map[string]OpenAPIResponse{
+			"200": documentResponse("The resource", name+"Document"),
+		})
+	}
+
+	if _, ok := res.source.(ResourceUpdater); ok && allows(OperationUpdate, "") {
+		schemas[name+"UpdateDocument"] = OpenAPISchema{
+			"type":       "object",
+			"required":   []string{"data"},
+			"properties": map[string]interface{}{"data": res.resourceObjectSchema("type", "id")},
+		}
+
+		single["patch"] = withBody(operation(string(OperationUpdate), "Update a "+name+" resource", append([]OpenAPIParameter{idParameter}, includeParameters...), map[string]OpenAPIResponse{
+			"200": documentResponse("The updated resource", name+"Document"),
+			"202": {Description: "Accepted"},
+			"204": {Description: "Updated"},
+		}), openAPIRef("schemas", name+"UpdateDocument"))
+	}
+
+	if _, ok := res.source.(ResourceDeleter); ok && allows(OperationDelete, "") {
+		single["delete"] = operation(string(OperationDelete), "Delete a "+name+" resource", []OpenAPIParameter{idParameter}, map[string]OpenAPIResponse{
+			"200": documentResponse("Deleted", "MetaDocument"),
+			"202": {Description: "Accepted"},
+			"204": {Description: "Deleted"},
+		})
+	}
+
+	if len(collection) > 0 {
+		document.Paths[baseURL] = collection
+	}
+
+	if len(single) > 0 {
+		document.Paths[elementURL] = single
+	}
+
+	_, editToMany := reflect.New(resourceType).Interface().(jsonapi.EditToManyRelations)
+	for _, relation := range res.references() {
+		relationship := "ToOneRelationship"
+		if isToManyRelation(relation) {
+			relationship = "ToManyRelationship"
+		}
+
+		related := OpenAPIPathItem{}
+		relatedParameters := []OpenAPIParameter{idParameter}
+		relatedResponse := documentResponse("The related resource", "Document")
+		if linked := res.api.resourceByName(relation.Type); linked != nil {
+			relatedResponse = documentResponse("The related resource", linked.name+"Document")
+			if isToManyRelation(relation) {
+				relatedParameters = append(relatedParameters, linked.collectionParameters()...)
+				relatedResponse = documentResponse("The related resources", linked.name+"CollectionDocument")
+			}
+		} else if isToManyRelation(relation) {
+			relatedResponse = documentResponse("The related resources", "CollectionDocument")
+		}
+
+		path := res.config.relationshipPath(relation.Name)
+		if allows(OperationReadRelated, relation.Name) {
+			related["get"] = operation(relation.Name+"."+string(OperationReadRelated), "Read the "+relation.Name+" of a "+name+" resource", relatedParameters, map[string]OpenAPIResponse{
+				"200": relatedResponse,
+			})
+			document.Paths[elementURL+"/"+path] = related
+		}
+
+		relationshipItem := OpenAPIPathItem{}
+		if allows(OperationReadRelationship, relation.Name) {
+			relationshipItem["get"] = operation(relation.Name+"."+string(OperationReadRelationship), "Read the "+relation.Name+" relationship of a "+name+" resource", []OpenAPIParameter{idParameter}, map[string]OpenAPIResponse{
+				"200": documentResponse("The relationship", relationship),
+			})
+		}
+
+		if allows(OperationReplaceRelationship, relation.Name) {
+			relationshipItem["patch"] = withBody(operation(relation.Name+"."+string(OperationReplaceRelationship), "Replace the "+relation.Name+" relationship of a "+name+" resource", []OpenAPIParameter{idParameter}, map[string]OpenAPIResponse{
+				"204": {Description: "Replaced"},
+			}), openAPIRef("schemas", relationship))
+		}
+
+		if editToMany && relation.Name == jsonapi.Pluralize(relation.Name) {
+			if allows(OperationAddToRelationship, relation.Name) {
+				relationshipItem["post"] = withBody(operation(relation.Name+"."+string(OperationAddToRelationship), "Add to the "+relation.Name+" relationship of a "+name+" resource", []OpenAPIParameter{idParameter}, map[string]OpenAPIResponse{
+					"204": {Description: "Added"},
+				}), openAPIRef("schemas", "ToManyRelationship"))
+			}
+
+			if allows(OperationRemoveFromRelationship, relation.Name) {
+				relationshipItem["delete"] = withBody(operation(relation.Name+"."+string(OperationRemoveFromRelationship), "Remove from the "+relation.Name+" relationship of a "+name+" resource", []OpenAPIParameter{idParameter}, map[string]OpenAPIResponse{
+					"204": {Description: "Removed"},
+				}), openAPIRef("schemas", "ToManyRelationship"))
+			}
+		}
+
+		if len(relationshipItem) > 0 {
+			document.Paths[elementURL+"/relationships/"+path] = relationshipItem
+		}
+	}
+}
+
+// openAPIPath converts the parameters of a route from :name to {name}
+func openAPIPath(route string) string {
+	segments := strings.Split(route, "/")
+	for index, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[index] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// openAPIPathParameter describes a required path parameter
+func openAPIPathParameter(name string) OpenAPIParameter {
+	return OpenAPIParameter{Name: name, In: "path", Required: true, Schema: OpenAPISchema{"type": "string"}}
+}
+
+// addOpenAPIOperations documents the endpoint of the atomic operations extension
+func (api *API) addOpenAPIOperations(document *OpenAPIDocument) {
+	contentType := fmt.Sprintf(`%s; ext="%s"`, api.ContentType, atomicExtension)
+
+	document.Components.Schemas["AtomicOperationsDocument"] = OpenAPISchema{
+		"type":     "object",
+		"required": []string{"atomic:operations"},
+		"properties": map[string]interface{}{
+			"atomic:operations": OpenAPISchema{
+				"type": "array",
+				"items": OpenAPISchema{
+					"type":     "object",
+					"required": []string{"op"},
+					"properties": map[string]interface{}{
+						"op": OpenAPISchema{"type": "string", "enum": []string{atomicOperationAdd, atomicOperationUpdate, atomicOperationRemove}},
+						"ref": OpenAPISchema{
+							"type":     "object",
+							"required": []string{"type"},
+							"properties": map[string]interface{}{
+								"type":         OpenAPISchema{"type": "string"},
+								"id":           OpenAPISchema{"type": "string"},
+								"lid":          OpenAPISchema{"type": "string"},
+								"relationship": OpenAPISchema{"type": "string"},
+							},
+						},
+						"href": OpenAPISchema{"type": "string"},
+						"data": OpenAPISchema{},
+					},
+				},
+			},
+		},
+	}
+
+	document.Components.Schemas["AtomicResultsDocument"] = OpenAPISchema{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"atomic:results": OpenAPISchema{
+				"type": "array",
+				"items": OpenAPISchema{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"data": openAPIRef("schemas", "Resource"),
+						"meta": openAPIRef("schemas", "Meta"),
+					},
+				},
+			},
+		},
+	}
+
+	document.Paths[api.routePath(atomicOperationsPath)] = OpenAPIPathItem{
+		"post": {
+			OperationID: "operations",
+			Summary:     "Execute atomic operations",
+			RequestBody: &OpenAPIRequestBody{
+				Required: true,
+				Content:  openAPIContent(contentType, openAPIRef("schemas", "AtomicOperationsDocument")),
+			},
+			Responses: map[string]OpenAPIResponse{
+				"200":     {Description: "The results of the operations", Content: openAPIContent(contentType, openAPIRef("schemas", "AtomicResultsDocument"))},
+				"204":     {Description: "All operations succeeded without results"},
+				"default": {Ref: "#/components/responses/Error"},
+			},
+		},
+	}
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+// openAPITypeSchema reflects the JSON Schema of the json encoding of t. seen holds the
+// struct types that are currently being reflected to stop at recursive types.
+func openAPITypeSchema(t reflect.Type, seen map[reflect.Type]bool) OpenAPISchema {
+	if t == timeType {
+		return OpenAPISchema{"type": "string", "format": "date-time"}
+	}
+
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return OpenAPISchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := openAPITypeSchema(t.Elem(), seen)
+		if schemaType, ok := schema["type"].(string); ok {
+			schema["type"] = []string{schemaType, "null"}
+		}
+		return schema
+	case reflect.Bool:
+		return OpenAPISchema{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return OpenAPISchema{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return OpenAPISchema{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return OpenAPISchema{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return OpenAPISchema{"type": "number", "format": "double"}
+	case reflect.String:
+		return OpenAPISchema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return OpenAPISchema{"type": "string", "format": "byte"}
+		}
+		return OpenAPISchema{"type": "array", "items": openAPITypeSchema(t.Elem(), seen)}
+	case reflect.Map:
+		return OpenAPISchema{"type": "object", "additionalProperties": openAPITypeSchema(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return OpenAPISchema{"type": "object"}
+		}
+
+		seen[t] = true
+		properties := map[string]interface{}{}
+		addOpenAPIProperties(t, properties, seen)
+		delete(seen, t)
+
+		return OpenAPISchema{"type": "object", "properties": properties}
+	}
+
+	return OpenAPISchema{}
+}
+
+// addOpenAPIProperties adds the schemas of all fields of t that are encoded by
+// encoding/json. Fields of embedded structs without json name are promoted.
+func addOpenAPIProperties(t reflect.Type, properties map[string]interface{}, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+
+			if fieldType.Kind() == reflect.Struct {
+				addOpenAPIProperties(fieldType, properties, seen)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := openAPITypeSchema(field.Type, seen)
+		for _, option := range strings.Split(options, ",") {
+			if option == "string" {
+				schema = OpenAPISchema{"type": "string"}
+			}
+		}
+
+		properties[name] = schema
+	}
+}
@@ -1,12 +1,42 @@
 package api2go
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/manyminds/api2go/jsonapi"
+)
 
 // Request contains additional information for FindOne and Find Requests
 type Request struct {
 	PlainRequest *http.Request
 	QueryParams  map[string][]string
 	Pagination   map[string]string
-	Header       http.Header
-	Context      APIContexter
+	// Include holds the parsed `include` query parameter. It is nil if the
+	// client did not send one, which means that all referenced structs will be
+	// included in the response.
+	Include jsonapi.IncludePaths
+	// Sort holds the parsed `sort` query parameter in the requested order. It
+	// is nil if the client did not send one.
+	Sort []SortField
+	// Filters holds the parsed top level `filter[field]` and
+	// `filter[field][operator]` query parameters, sorted by field and operator.
+	// It is nil if the client did not send any.
+	Filters []Filter
+	// FilterGroups holds the parsed `filter[or]` and `filter[and]` groups, see
+	// FilterGroup. All Filters and FilterGroups have to match. It is nil if the
+	// client did not send any.
+	FilterGroups []FilterGroup
+	// Extensions holds the registered JSON:API extensions that the client
+	// applied with the `ext` media type parameter of the Content-Type header.
+	Extensions []string
+	// Profiles holds the registered JSON:API profiles that the client
+	// requested with the `profile` media type parameter. They are added to
+	// the Content-Type of the response.
+	Profiles []string
+	// ParentIDs holds the parameters of the parent path of nested resources by name,
+	// for example {"storeID": "42"} for /stores/42/devices, see Under. It is nil for
+	// other resources.
+	ParentIDs map[string]string
+	Header    http.Header
+	Context   APIContexter
 }
@@ -0,0 +1,176 @@
+package api2go
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Operation is an operation of a resource that can be disabled with DisableOperations
+// or DisableRelationshipOperations. The values are used as operation ids in the
+// OpenAPI document.
+type Operation string
+
+// The operations of a resource
+const (
+	// OperationList lists the collection, GET /users
+	OperationList Operation = "list"
+	// OperationRead reads a single resource, GET /users/:id
+	OperationRead Operation = "read"
+	// OperationCreate creates a resource, POST /users
+	OperationCreate Operation = "create"
+	// OperationUpdate updates a resource, PATCH /users/:id
+	OperationUpdate Operation = "update"
+	// OperationDelete deletes a resource, DELETE /users/:id
+	OperationDelete Operation = "delete"
+	// OperationReadRelated reads the resources of a relationship, GET /users/:id/posts
+	OperationReadRelated Operation = "readRelated"
+	// OperationReadRelationship reads the linkage of a relationship,
+	// GET /users/:id/relationships/posts
+	OperationReadRelationship Operation = "readRelationship"
+	// OperationReplaceRelationship replaces the linkage of a relationship,
+	// PATCH /users/:id/relationships/posts
+	OperationReplaceRelationship Operation = "replaceRelationship"
+	// OperationAddToRelationship adds to a to-many relationship,
+	// POST /users/:id/relationships/posts
+	OperationAddToRelationship Operation = "addToRelationship"
+	// OperationRemoveFromRelationship removes from a to-many relationship,
+	// DELETE /users/:id/relationships/posts
+	OperationRemoveFromRelationship Operation = "removeFromRelationship"
+)
+
+// RelationshipWriteOperations are all operations that change relationships. Disabling
+// them makes relationships read-only.
+var RelationshipWriteOperations = []Operation{
+	OperationReplaceRelationship,
+	OperationAddToRelationship,
+	OperationRemoveFromRelationship,
+}
+
+// ResourceOption configures the routes that AddResource generates for a resource
+type ResourceOption func(config *resourceConfig)
+
+// resourceConfig contains the configuration of the ResourceOptions of a resource
+type resourceConfig struct {
+	disabled              map[Operation]bool
+	disabledRelationships map[string]map[Operation]bool
+	relationshipPaths     map[string]string
+	parent                string
+	idParam               string
+}
+
+// DisableOperations disables operations of a resource. No routes are generated for
+// them, they are not part of the Allow header and the OpenAPI document and atomic
+// operations reject them. Relationship operations are disabled for all relationships.
+//
+//	api.AddResource(User{}, source, api2go.DisableOperations(api2go.OperationDelete))
+func DisableOperations(operations ...Operation) ResourceOption {
+	return func(config *resourceConfig) {
+		if config.disabled == nil {
+			config.disabled = map[Operation]bool{}
+		}
+
+		for _, operation := range operations {
+			config.disabled[operation] = true
+		}
+	}
+}
+
+// DisableRelationshipOperations disables operations of one relationship, see
+// DisableOperations. Use RelationshipWriteOperations to make a relationship read-only.
+func DisableRelationshipOperations(relationship string, operations ...Operation) ResourceOption {
+	return func(config *resourceConfig) {
+		if config.disabledRelationships == nil {
+			config.disabledRelationships = map[string]map[Operation]bool{}
+		}
+
+		if config.disabledRelationships[relationship] == nil {
+			config.disabledRelationships[relationship] = map[Operation]bool{}
+		}
+
+		for _, operation := range operations {
+			config.disabledRelationships[relationship][operation] = true
+		}
+	}
+}
+
+// RelationshipPath serves a relationship at another path than its name, for example
+// /users/:id/chocolates and /users/:id/relationships/chocolates for the relationship
+// sweets. The name in documents stays the same, the links use the path.
+func RelationshipPath(relationship, path string) ResourceOption {
+	return func(config *resourceConfig) {
+		if config.relationshipPaths == nil {
+			config.relationshipPaths = map[string]string{}
+		}
+
+		config.relationshipPaths[relationship] = path
+	}
+}
+
+// Under mounts the routes of a resource below a path, for example "stores/:storeID" for
+// /stores/:storeID/devices. The parameters of the path are available in Request.ParentIDs
+// and are used in the links of the resource, see ParentIdentifier. A path without
+// parameters only namespaces the routes, for example "admin" for /admin/devices.
+func Under(path string) ResourceOption {
+	return func(config *resourceConfig) {
+		config.parent = strings.Trim(path, "/")
+	}
+}
+
+// IDParam changes the name of the id parameter in the routes of a resource, which is
+// "id" by default. Routers like httprouter and gin require the same name for parameters
+// at the same position, so a resource with nested resources below "stores/:storeID" must
+// be registered with IDParam("storeID").
+func IDParam(name string) ResourceOption {
+	return func(config *resourceConfig) {
+		config.idParam = name
+	}
+}
+
+// allows returns true if the operation has not been disabled. relationship is the name
+// of the relationship for relationship operations and empty otherwise.
+func (c resourceConfig) allows(operation Operation, relationship string) bool {
+	if c.disabled[operation] {
+		return false
+	}
+
+	return relationship == "" || !c.disabledRelationships[relationship][operation]
+}
+
+// relationshipPath returns the path of the routes of a relationship
+func (c resourceConfig) relationshipPath(relationship string) string {
+	if path, ok := c.relationshipPaths[relationship]; ok {
+		return path
+	}
+
+	return relationship
+}
+
+// checkOperation returns 403 Forbidden if the operation has been disabled
+func (res *resource) checkOperation(operation Operation, relationship string) error {
+	if res.config.allows(operation, relationship) {
+		return nil
+	}
+
+	return NewForbiddenError(nil, fmt.Sprintf("Operation %s is disabled for resource %s", operation, res.name))
+}
+
+// allowedMethods returns the methods of all routes of the resource with the given path
+// for the Allow header
+func (res *resource) allowedMethods(path string) []string {
+	registered := map[string]bool{}
+	for _, route := range res.api.routes {
+		if route.Resource == res.name && route.Path == path {
+			registered[route.Method] = true
+		}
+	}
+
+	var result []string
+	for _, method := range []string{http.MethodOptions, http.MethodGet, http.MethodPatch, http.MethodDelete, http.MethodPost} {
+		if registered[method] {
+			result = append(result, method)
+		}
+	}
+
+	return result
+}
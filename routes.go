@@ -0,0 +1,68 @@
+package api2go
+
+import "fmt"
+
+// RouteKind describes what a generated route does
+type RouteKind string
+
+// The kinds of the generated routes
+const (
+	// RouteIndex lists a collection, GET /users
+	RouteIndex RouteKind = "index"
+	// RouteRead reads a single resource, GET /users/:id
+	RouteRead RouteKind = "read"
+	// RouteRelated reads the resources of a relationship, GET /users/:id/posts
+	RouteRelated RouteKind = "related"
+	// RouteRelationship reads or changes the linkage of a relationship,
+	// GET, PATCH, POST and DELETE /users/:id/relationships/posts
+	RouteRelationship RouteKind = "relationship"
+	// RouteCreate creates a resource, POST /users
+	RouteCreate RouteKind = "create"
+	// RouteUpdate updates a resource, PATCH /users/:id
+	RouteUpdate RouteKind = "update"
+	// RouteDelete deletes a resource, DELETE /users/:id
+	RouteDelete RouteKind = "delete"
+	// RouteOptions answers with the allowed methods, OPTIONS /users and /users/:id
+	RouteOptions RouteKind = "options"
+	// RouteOperations executes atomic operations, see API.EnableAtomicOperations
+	RouteOperations RouteKind = "operations"
+	// RouteOpenAPI serves the OpenAPI document, see API.ServeOpenAPI
+	RouteOpenAPI RouteKind = "openapi"
+)
+
+// Route describes a route that api2go has registered at the router
+type Route struct {
+	// Method is the http method of the route
+	Method string
+	// Path is the route as passed to the router, parameters are written as :name
+	Path string
+	// Resource is the name of the resource, it is empty for routes that do not belong
+	// to a resource
+	Resource string
+	// Relationship is the name of the relationship for related and relationship routes
+	Relationship string
+	// Kind describes what the route does
+	Kind RouteKind
+}
+
+// String returns the method and path of the route followed by its resource,
+// relationship and kind, for example "GET /v1/users/:id/posts (users posts related)"
+func (r Route) String() string {
+	description := string(r.Kind)
+	if r.Relationship != "" {
+		description = r.Relationship + " " + description
+	}
+
+	if r.Resource != "" {
+		description = r.Resource + " " + description
+	}
+
+	return fmt.Sprintf("%s %s (%s)", r.Method, r.Path, description)
+}
+
+// Routes returns all routes that have been registered by the api in the order of their
+// registration. This is useful for debug endpoints, for logging the routes on startup or
+// for tests that catch accidental changes of the routes.
+func (api *API) Routes() []Route {
+	return append([]Route(nil), api.routes...)
+}
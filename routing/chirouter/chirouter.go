@@ -0,0 +1,51 @@
+// Package chirouter provides an api2go router for chi.
+package chirouter
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Router implements routing.Routeable for a chi router, the :name parameters of the
+// routes are registered in the {name} notation of chi.
+type Router struct {
+	router chi.Router
+}
+
+// Handler returns the chi router
+func (c Router) Handler() http.Handler {
+	return c.router
+}
+
+// Handle registers handler for the method protocol and the route
+func (c Router) Handle(protocol, route string, handler func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{})) {
+	wrappedHandler := func(w http.ResponseWriter, r *http.Request) {
+		params := map[string]string{}
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			for i, key := range rctx.URLParams.Keys {
+				params[key] = rctx.URLParams.Values[i]
+			}
+		}
+
+		handler(w, r, params, make(map[string]interface{}))
+	}
+
+	// The request path will have parameterized segments indicated as :name. Convert
+	// that notation to the {name} notation used by chi.
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	c.router.MethodFunc(protocol, strings.Join(segments, "/"), wrappedHandler)
+}
+
+// New creates a new api2go router to use with chi. Any chi.Router can be used, for
+// example a chi.Mux or a router returned by Route or Group.
+func New(router chi.Router) *Router {
+	return &Router{router: router}
+}
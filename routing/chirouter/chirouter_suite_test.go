@@ -0,0 +1,13 @@
+package chirouter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestChirouter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Chirouter Suite")
+}
@@ -0,0 +1,40 @@
+// Package echorouter provides an api2go router for the echo framework.
+package echorouter
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+)
+
+// Router implements routing.Routeable for an echo instance. api2go writes its responses
+// and errors itself, so the echo handlers always return nil.
+type Router struct {
+	echo *echo.Echo
+}
+
+// Handler returns the echo instance
+func (e Router) Handler() http.Handler {
+	return e.echo
+}
+
+// Handle registers handler for the method protocol and the route
+func (e Router) Handle(protocol, route string, handler func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{})) {
+	echoHandlerFunc := func(c echo.Context) error {
+		params := map[string]string{}
+
+		for i, p := range c.ParamNames() {
+			params[p] = c.ParamValues()[i]
+		}
+
+		handler(c.Response(), c.Request(), params, make(map[string]interface{}))
+
+		return nil
+	}
+	e.echo.Add(protocol, route, echoHandlerFunc)
+}
+
+// New creates a new api2go router to use with the echo framework
+func New(e *echo.Echo) *Router {
+	return &Router{echo: e}
+}
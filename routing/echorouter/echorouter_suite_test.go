@@ -0,0 +1,13 @@
+package echorouter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestEchorouter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Echorouter Suite")
+}
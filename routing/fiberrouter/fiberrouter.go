@@ -0,0 +1,123 @@
+// Package fiberrouter provides an api2go router for the fiber framework.
+//
+// Fiber is built on fasthttp, every request is converted to a net/http request before
+// it is passed to api2go and the response is converted back. Responses are buffered
+// completely, so streamed collections are not sent before they are complete. Like with
+// app.Listen, routes that are added to the app directly after the first request are
+// not served.
+package fiberrouter
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/valyala/fasthttp"
+)
+
+// userContextKey is the key of the fiber UserContext in the values of the fasthttp context
+const userContextKey = "__local_user_context__"
+
+// Router implements routing.Routeable for a fiber app. The locals of the fiber context
+// are passed to api2go, so values set by fiber middlewares are available in the
+// APIContext.
+type Router struct {
+	app     *fiber.App
+	handler atomic.Pointer[fasthttp.RequestHandler]
+}
+
+// Handler returns the fiber app as net/http handler. The context of the request is
+// available as UserContext of the fiber context.
+func (f *Router) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+
+		req.Header.SetMethod(r.Method)
+		req.SetRequestURI(r.URL.RequestURI())
+		req.SetHost(r.Host)
+		for key, values := range r.Header {
+			for _, value := range values {
+				req.Header.Add(key, value)
+			}
+		}
+
+		if r.Body != nil {
+			n, err := io.Copy(req.BodyWriter(), r.Body)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			req.Header.SetContentLength(int(n))
+		}
+
+		var remoteAddr net.Addr
+		if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+			remoteAddr = addr
+		}
+
+		var ctx fasthttp.RequestCtx
+		ctx.Init(req, remoteAddr, nil)
+		ctx.SetUserValue(userContextKey, r.Context())
+		f.requestHandler()(&ctx)
+
+		ctx.Response.Header.VisitAll(func(key, value []byte) {
+			w.Header().Add(string(key), string(value))
+		})
+		w.WriteHeader(ctx.Response.StatusCode())
+		_, _ = w.Write(ctx.Response.Body())
+	})
+}
+
+// Handle registers handler for the method protocol and the route
+func (f *Router) Handle(protocol, route string, handler func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{})) {
+	fiberHandler := func(c *fiber.Ctx) error {
+		params := map[string]string{}
+		for _, key := range c.Route().Params {
+			params[key] = c.Params(key)
+		}
+
+		values := map[string]interface{}{}
+		c.Context().VisitUserValues(func(key []byte, value interface{}) {
+			// the UserContext is stored next to the locals
+			if string(key) != userContextKey {
+				values[string(key)] = value
+			}
+		})
+
+		return adaptor.HTTPHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ctx, ok := c.Context().UserValue(userContextKey).(context.Context); ok {
+				r = r.WithContext(ctx)
+			}
+
+			handler(w, r, params, values)
+		})(c)
+	}
+
+	f.app.Add(protocol, route, fiberHandler)
+	f.handler.Store(nil)
+}
+
+// requestHandler returns the handler of the app. The route tree of the app is only
+// updated by its Handler method, so the handler is built again after routes have been
+// added with Handle.
+func (f *Router) requestHandler() fasthttp.RequestHandler {
+	if handler := f.handler.Load(); handler != nil {
+		return *handler
+	}
+
+	handler := f.app.Handler()
+	f.handler.Store(&handler)
+	return handler
+}
+
+// New creates a new api2go router to use with the fiber framework. Values that have
+// been stored with string keys in the Locals of the fiber context are copied to the
+// api2go context.
+func New(app *fiber.App) *Router {
+	return &Router{app: app}
+}
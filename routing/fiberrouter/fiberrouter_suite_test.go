@@ -0,0 +1,13 @@
+package fiberrouter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestFiberrouter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fiberrouter Suite")
+}
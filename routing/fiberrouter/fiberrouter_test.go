@@ -0,0 +1,176 @@
+package fiberrouter_test
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/manyminds/api2go"
+	"github.com/manyminds/api2go/examples/model"
+	"github.com/manyminds/api2go/examples/resource"
+	"github.com/manyminds/api2go/examples/storage"
+	"github.com/manyminds/api2go/routing"
+	"github.com/manyminds/api2go/routing/fiberrouter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("api2go with fiber router adapter", func() {
+	var (
+		router       routing.Routeable
+		app          *fiber.App
+		api          *api2go.API
+		rec          *httptest.ResponseRecorder
+		contextKey   = "userID"
+		contextValue *string
+		received     interface{}
+	)
+
+	BeforeSuite(func() {
+		app = fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			if contextValue != nil {
+				c.Locals(contextKey, *contextValue)
+			}
+			return c.Next()
+		})
+		router = fiberrouter.New(app)
+		api = api2go.NewAPIWithRouting(
+			"api",
+			api2go.NewStaticResolver("/"),
+			router,
+		)
+
+		userStorage := storage.NewUserStorage()
+		chocStorage := storage.NewChocolateStorage()
+		api.AddResource(model.User{}, resource.UserResource{ChocStorage: chocStorage, UserStorage: userStorage})
+		api.AddResource(model.Chocolate{}, resource.ChocolateResource{ChocStorage: chocStorage, UserStorage: userStorage})
+		api.UseMiddleware(func(c api2go.APIContexter, w http.ResponseWriter, r *http.Request) {
+			received, _ = c.Get(contextKey)
+		})
+	})
+
+	BeforeEach(func() {
+		log.SetOutput(io.Discard)
+		rec = httptest.NewRecorder()
+	})
+
+	Context("CRUD Tests", func() {
+		It("will create a new user", func() {
+			reqBody := strings.NewReader(`{"data": {"attributes": {"user-name": "Sansa Stark"}, "id": "1", "type": "users"}}`)
+			req, err := http.NewRequest("POST", "/api/users", reqBody)
+			Expect(err).To(BeNil())
+			router.Handler().ServeHTTP(rec, req)
+			Expect(rec.Code).To(Equal(http.StatusCreated))
+		})
+
+		It("will find her", func() {
+			expectedUser := `
+			{
+				"data":
+				{
+					"attributes":{
+						"user-name":"Sansa Stark"
+					},
+					"id":"1",
+					"relationships":{
+						"sweets":{
+							"data":[],"links":{"related":"/api/users/1/sweets","self":"/api/users/1/relationships/sweets"}
+						}
+					},"type":"users"
+				},
+				"meta":
+				{
+					"author":"The api2go examples crew","license":"wtfpl","license-url":"http://www.wtfpl.net"
+				}
+			}`
+
+			req, err := http.NewRequest("GET", "/api/users/1", nil)
+			Expect(err).To(BeNil())
+			router.Handler().ServeHTTP(rec, req)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(string(rec.Body.Bytes())).To(MatchJSON((expectedUser)))
+		})
+
+		It("update the username", func() {
+			reqBody := strings.NewReader(`{"data": {"id": "1", "attributes": {"user-name": "Alayne"}, "type" : "users"}}`)
+			req, err := http.NewRequest("PATCH", "/api/users/1", reqBody)
+			Expect(err).To(BeNil())
+			router.Handler().ServeHTTP(rec, req)
+			Expect(rec.Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("will find her once again", func() {
+			expectedUser := `
+			{
+				"data":
+				{
+					"attributes":{
+						"user-name":"Alayne"
+					},
+					"id":"1",
+					"relationships":{
+						"sweets":{
+							"data":[],"links":{"related":"/api/users/1/sweets","self":"/api/users/1/relationships/sweets"}
+						}
+					},"type":"users"
+				},
+				"meta":
+				{
+					"author":"The api2go examples crew","license":"wtfpl","license-url":"http://www.wtfpl.net"
+				}
+			}`
+
+			req, err := http.NewRequest("GET", "/api/users/1", nil)
+			Expect(err).To(BeNil())
+			router.Handler().ServeHTTP(rec, req)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(string(rec.Body.Bytes())).To(MatchJSON((expectedUser)))
+		})
+
+		It("will delete her", func() {
+			req, err := http.NewRequest("DELETE", "/api/users/1", nil)
+			Expect(err).To(BeNil())
+			router.Handler().ServeHTTP(rec, req)
+			Expect(rec.Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("won't find her anymore", func() {
+			expected := `{"errors":[{"status":"404","title":"http error (404) User for id 1 not found and 0 more errors, User for id 1 not found"}]}`
+			req, err := http.NewRequest("GET", "/api/users/1", nil)
+			Expect(err).To(BeNil())
+			router.Handler().ServeHTTP(rec, req)
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+			Expect(string(rec.Body.Bytes())).To(MatchJSON(expected))
+		})
+	})
+
+	Context("Fiber Locals Copy Tests", func() {
+		BeforeEach(func() {
+			contextValue = nil
+			received = nil
+		})
+
+		It("context value is present for chocolate resource", func() {
+			tempVal := "1"
+			contextValue = &tempVal
+			req, err := http.NewRequest("GET", "/api/chocolates", strings.NewReader(""))
+			Expect(err).To(BeNil())
+			router.Handler().ServeHTTP(rec, req)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(received).To(Equal("1"))
+		})
+
+		It("context value is not present for chocolate resource", func() {
+			req, err := http.NewRequest("GET", "/api/chocolates", strings.NewReader(""))
+			Expect(err).To(BeNil())
+			router.Handler().ServeHTTP(rec, req)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(received).To(BeNil())
+		})
+	})
+})
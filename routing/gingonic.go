@@ -1,36 +1,15 @@
-//go:build gingonic && !gorillamux && !echo
-// +build gingonic,!gorillamux,!echo
+//go:build gingonic
 
 package routing
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
+	"github.com/manyminds/api2go/routing/ginrouter"
 )
 
-type ginRouter struct {
-	router *gin.Engine
-}
-
-func (g ginRouter) Handler() http.Handler {
-	return g.router
-}
-
-func (g ginRouter) Handle(protocol, route string, handler HandlerFunc) {
-	wrappedCallback := func(c *gin.Context) {
-		params := map[string]string{}
-		for _, p := range c.Params {
-			params[p.Key] = p.Value
-		}
-
-		handler(c.Writer, c.Request, params, c.Keys)
-	}
-
-	g.router.Handle(protocol, route, wrappedCallback)
-}
-
 // Gin creates a new api2go router to use with the gin framework
+//
+// Deprecated: use ginrouter.New, which does not need the gingonic build tag.
 func Gin(g *gin.Engine) Routeable {
-	return &ginRouter{router: g}
+	return ginrouter.New(g)
 }
@@ -0,0 +1,38 @@
+// Package ginrouter provides an api2go router for the gin framework.
+package ginrouter
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Router implements routing.Routeable for a gin engine. The keys of the gin context are
+// passed to api2go, so values set by gin middlewares are available in the APIContext.
+type Router struct {
+	router *gin.Engine
+}
+
+// Handler returns the gin engine
+func (g Router) Handler() http.Handler {
+	return g.router
+}
+
+// Handle registers handler for the method protocol and the route
+func (g Router) Handle(protocol, route string, handler func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{})) {
+	wrappedCallback := func(c *gin.Context) {
+		params := map[string]string{}
+		for _, p := range c.Params {
+			params[p.Key] = p.Value
+		}
+
+		handler(c.Writer, c.Request, params, c.Keys)
+	}
+
+	g.router.Handle(protocol, route, wrappedCallback)
+}
+
+// New creates a new api2go router to use with the gin framework
+func New(g *gin.Engine) *Router {
+	return &Router{router: g}
+}
@@ -0,0 +1,13 @@
+package ginrouter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGinrouter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Ginrouter Suite")
+}
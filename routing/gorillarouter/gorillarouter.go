@@ -0,0 +1,47 @@
+// Package gorillarouter provides an api2go router for the Gorilla mux framework.
+package gorillarouter
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Router implements routing.Routeable for a Gorilla mux router, the :name parameters of
+// the routes are registered in the {name} notation of Gorilla mux.
+type Router struct {
+	router *mux.Router
+}
+
+// Handler returns the Gorilla mux router
+func (gm Router) Handler() http.Handler {
+	return gm.router
+}
+
+// Handle registers handler for the method protocol and the route
+func (gm Router) Handle(protocol, route string, handler func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{})) {
+	wrappedHandler := func(w http.ResponseWriter, r *http.Request) {
+		handler(w, r, mux.Vars(r), make(map[string]interface{}))
+	}
+
+	// The request path will have parameterized segments indicated as :name.  Convert
+	// that notation to the {name} notation used by Gorilla mux.
+	orig := strings.Split(route, "/")
+	var mod []string
+	for _, s := range orig {
+		if len(s) > 0 && s[0] == ':' {
+			s = fmt.Sprintf("{%s}", s[1:])
+		}
+		mod = append(mod, s)
+	}
+	modroute := strings.Join(mod, "/")
+
+	gm.router.HandleFunc(modroute, wrappedHandler).Methods(protocol)
+}
+
+// New creates a new api2go router to use with the Gorilla mux framework
+func New(gm *mux.Router) *Router {
+	return &Router{router: gm}
+}
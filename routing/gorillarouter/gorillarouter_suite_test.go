@@ -0,0 +1,13 @@
+package gorillarouter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGorillarouter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Gorillarouter Suite")
+}
@@ -3,8 +3,11 @@ package routing
 import "net/http"
 
 // HandlerFunc must contain all params from the route
-// in the form key,value
-type HandlerFunc func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{})
+// in the form key,value. It is an alias, so that the router adapters below routing can
+// implement Routeable without importing this package. The adapters do not import
+// routing, which imports them for its deprecated constructors, so their Handle methods
+// spell out this type.
+type HandlerFunc = func(w http.ResponseWriter, r *http.Request, params map[string]string, context map[string]interface{})
 
 // Routeable allows drop in replacement for api2go's router
 // by default, we are using julienschmidt/httprouter
@@ -0,0 +1,62 @@
+package routing
+
+import (
+	"net/http"
+	"strings"
+)
+
+type serveMuxRouter struct {
+	mux *http.ServeMux
+}
+
+func (sm serveMuxRouter) Handler() http.Handler {
+	return sm.mux
+}
+
+func (sm serveMuxRouter) Handle(protocol, route string, handler HandlerFunc) {
+	pattern, names := serveMuxPattern(route)
+
+	wrappedHandler := func(w http.ResponseWriter, r *http.Request) {
+		params := make(map[string]string, len(names))
+		for _, name := range names {
+			params[name] = r.PathValue(name)
+		}
+
+		handler(w, r, params, make(map[string]interface{}))
+	}
+
+	sm.mux.HandleFunc(protocol+" "+pattern, wrappedHandler)
+}
+
+// serveMuxPattern converts the :name and *name segments of an api2go route into the
+// {name} and {name...} wildcards of http.ServeMux and returns the names of all of them.
+// Routes ending with a slash only match that exact path, like with the other routers.
+func serveMuxPattern(route string) (string, []string) {
+	var names []string
+
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			names = append(names, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		case strings.HasPrefix(segment, "*"):
+			names = append(names, segment[1:])
+			segments[i] = "{" + segment[1:] + "...}"
+		}
+	}
+
+	pattern := strings.Join(segments, "/")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "{$}"
+	}
+
+	return pattern, names
+}
+
+// ServeMux creates a new api2go router to use with the http.ServeMux of the standard
+// library. Requests with a method that is not registered for a path are answered by
+// the ServeMux with 405 Method Not Allowed.
+func ServeMux(mux *http.ServeMux) Routeable {
+	return &serveMuxRouter{mux: mux}
+}
@@ -0,0 +1,85 @@
+package api2go
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const codeInvalidQuerySort = "API2GO_INVALID_SORT_QUERY_PARAM"
+
+// SortField is one entry of the `sort` query parameter. A leading "-" in the
+// query parameter marks the field as descending.
+type SortField struct {
+	Name       string
+	Descending bool
+}
+
+// The SortableFields interface can be optionally implemented by a resource
+// source to declare which fields the collection can be sorted by. api2go will
+// answer requests with any other sort field with a 400 Bad Request before
+// FindAll or PaginatedFindAll is called.
+type SortableFields interface {
+	SortableFields() []string
+}
+
+// parseSortFields parses the sort query parameter of the request, it returns
+// nil if the parameter was not given.
+func parseSortFields(r *http.Request) []SortField {
+	value := r.URL.Query().Get("sort")
+	if value == "" {
+		return nil
+	}
+
+	result := []SortField{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		field := SortField{Name: strings.TrimPrefix(name, "-")}
+		field.Descending = field.Name != name
+		if field.Name == "" {
+			continue
+		}
+
+		result = append(result, field)
+	}
+
+	return result
+}
+
+// validateSort checks the requested sort fields against the fields declared by
+// a SortableFields source. Sources that do not implement it accept any field.
+func (res *resource) validateSort(fields []SortField) error {
+	source, ok := res.source.(SortableFields)
+	if !ok {
+		return nil
+	}
+
+	allowed := map[string]bool{}
+	for _, name := range source.SortableFields() {
+		allowed[name] = true
+	}
+
+	httpError := NewHTTPError(nil, "Some requested sort fields were invalid", http.StatusBadRequest)
+	for _, field := range fields {
+		if allowed[field.Name] {
+			continue
+		}
+
+		httpError.Errors = append(httpError.Errors, Error{
+			Status: strconv.Itoa(http.StatusBadRequest),
+			Code:   codeInvalidQuerySort,
+			Title:  fmt.Sprintf(`Sorting by "%s" is not supported for type "%s"`, field.Name, res.name),
+			Detail: "Please make sure you do only sort by supported fields",
+			Source: &ErrorSource{
+				Parameter: "sort",
+			},
+		})
+	}
+
+	if len(httpError.Errors) > 0 {
+		return httpError
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package api2go
+
+import (
+	"errors"
+	"iter"
+	"net/http"
+
+	"github.com/manyminds/api2go/jsonapi"
+)
+
+// The Streamer interface can be implemented by the Responder of FindAll,
+// PaginatedFindAll and CursorPaginatedFindAll. Instead of marshalling Result, the
+// resources of the stream are written to the response as they arrive, which bounds
+// the memory needed for large collections.
+//
+// The status code is sent with the first resource. An error of the stream before
+// that is handled like an error of the source, later errors are logged and abort the
+// response, so that clients can not mistake it for a complete document. Routers that
+// buffer the whole response, like fiber, send the truncated document instead.
+type Streamer interface {
+	Stream() iter.Seq2[jsonapi.MarshalIdentifier, error]
+}
+
+// StreamResponse is a Responder that streams Items, see Streamer. Meta and Pagination
+// of the embedded Response are written after the items.
+type StreamResponse struct {
+	Response
+	Items iter.Seq2[jsonapi.MarshalIdentifier, error]
+}
+
+// Stream returns the items of the response
+func (r StreamResponse) Stream() iter.Seq2[jsonapi.MarshalIdentifier, error] {
+	return r.Items
+}
+
+// StreamSeq converts a sequence of resources into a stream
+func StreamSeq[T jsonapi.MarshalIdentifier](items iter.Seq[T]) iter.Seq2[jsonapi.MarshalIdentifier, error] {
+	return func(yield func(jsonapi.MarshalIdentifier, error) bool) {
+		for item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+// StreamChannel converts a channel into a stream that ends when the channel is
+// closed. The stream stops early if the response can not be written, so the sender
+// should also stop once the context of the request is done.
+func StreamChannel[T jsonapi.MarshalIdentifier](items <-chan T) iter.Seq2[jsonapi.MarshalIdentifier, error] {
+	return func(yield func(jsonapi.MarshalIdentifier, error) bool) {
+		for item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+// stream writes the resources of streamer with a jsonapi.StreamEncoder
+func (res *resource) stream(streamer Streamer, obj Responder, info information, status int, links jsonapi.Links, w http.ResponseWriter, r *http.Request) error {
+	writer := &deferredWriter{
+		ResponseWriter: w,
+		status:         status,
+		contentType:    responseContentType(r, res.api.ContentType),
+		cacheHeaders: func() {
+			res.setCacheHeaders(w, r, obj)
+		},
+	}
+
+	encoder := jsonapi.NewStreamEncoder(writer, info, getIncludePaths(r))
+	query := r.URL.Query()
+	if fields := parseQueryFields(&query); len(fields) > 0 {
+		encoder.Filter = func(data *jsonapi.Data) error {
+			if wrongFields := replaceAttributes(&fields, data); len(wrongFields) > 0 {
+				return invalidFieldsError(wrongFields)
+			}
+			return nil
+		}
+	}
+
+	for element, err := range streamer.Stream() {
+		if err == nil {
+			err = encoder.Encode(element)
+		}
+
+		if err != nil {
+			return res.streamError(writer, r, err)
+		}
+	}
+
+	if err := encoder.Close(links, obj.Metadata()); err != nil {
+		return res.streamError(writer, r, err)
+	}
+
+	return nil
+}
+
+// streamError returns err if nothing has been written yet. Otherwise it is logged and
+// the response is aborted, so that clients can not mistake the truncated document for a
+// complete response.
+func (res *resource) streamError(writer *deferredWriter, r *http.Request, err error) error {
+	if !writer.written {
+		return err
+	}
+
+	res.api.logError(r, err)
+	if abortResponse(writer.ResponseWriter) {
+		return nil
+	}
+
+	return errAbortResponse
+}
+
+// errAbortResponse is returned by handlers if the response has been started, but could
+// not be aborted by closing the connection. The route aborts it with
+// http.ErrAbortHandler once the handler is done, if it is served by net/http.
+var errAbortResponse = errors.New("the response has been aborted")
+
+// abortResponse closes the connection of an HTTP/1 response without ending it. It returns
+// false if the connection can not be hijacked, for example for HTTP/2 or routers with
+// their own server. Closing it directly also works with routers that recover from panics.
+func abortResponse(w http.ResponseWriter) (aborted bool) {
+	defer func() {
+		// some writers of routers panic if the underlying writer can not be hijacked
+		if recover() != nil {
+			aborted = false
+		}
+	}()
+
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return false
+	}
+
+	_ = conn.Close()
+	return true
+}
+
+// servedByNetHTTP returns true if r is served by a net/http server, which is the only
+// one that recovers from http.ErrAbortHandler
+func servedByNetHTTP(r *http.Request) bool {
+	return r.Context().Value(http.ServerContextKey) != nil
+}
+
+// deferredWriter writes the header of a successful response with the first data
+type deferredWriter struct {
+	http.ResponseWriter
+	status       int
+	contentType  string
+	cacheHeaders func()
+	written      bool
+}
+
+func (w *deferredWriter) Write(data []byte) (int, error) {
+	if !w.written {
+		w.written = true
+		w.Header().Set("Content-Type", w.contentType)
+		w.cacheHeaders()
+		w.WriteHeader(w.status)
+	}
+
+	return w.ResponseWriter.Write(data)
+}
@@ -0,0 +1,62 @@
+package api2go
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/manyminds/api2go/jsonapi"
+)
+
+const codeValidationFailed = "API2GO_VALIDATION_FAILED"
+
+// FieldError describes why the value of an attribute is invalid
+type FieldError struct {
+	// Field is the name of the attribute as used in the json document
+	Field string
+	// Title is a short summary of the problem, for example "must not be empty"
+	Title string
+	// Detail optionally explains the problem in more detail
+	Detail string
+	// Code optionally overrides the default error code API2GO_VALIDATION_FAILED
+	Code string
+}
+
+// The Validator interface can be implemented by resource structs. Validate is called
+// after a request document has been unmarshalled on create and update, before any
+// hooks run. If it returns any FieldErrors, the request is answered with 422
+// Unprocessable Entity and one error object per FieldError, pointing to
+// `/data/attributes/<field>`.
+type Validator interface {
+	Validate(req Request) []FieldError
+}
+
+// validate calls Validate if obj implements the Validator interface
+func validate(obj interface{}, req Request) error {
+	validator, ok := obj.(Validator)
+	if !ok {
+		return nil
+	}
+
+	fieldErrors := validator.Validate(req)
+	if len(fieldErrors) == 0 {
+		return nil
+	}
+
+	httpError := NewHTTPError(nil, "Validation failed", http.StatusUnprocessableEntity)
+	for _, fieldError := range fieldErrors {
+		code := fieldError.Code
+		if code == "" {
+			code = codeValidationFailed
+		}
+
+		httpError.Errors = append(httpError.Errors, Error{
+			Status: strconv.Itoa(http.StatusUnprocessableEntity),
+			Code:   code,
+			Title:  fieldError.Title,
+			Detail: fieldError.Detail,
+			Source: &ErrorSource{Pointer: "/data/attributes/" + jsonapi.EscapePointerToken(fieldError.Field)},
+		})
+	}
+
+	return httpError
+}